@@ -0,0 +1,261 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package advisory matches product versions against known
+// vulnerabilities recorded in the OSV format.
+package advisory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chushi-io/lf-install/errors"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/chushi-io/lf-install/src"
+	"github.com/hashicorp/go-version"
+)
+
+// Action determines how versions affected by advisories are treated
+type Action int
+
+const (
+	// Warn logs affected versions, which are installed
+	// with advisories included in their details
+	Warn Action = iota
+
+	// Fail refuses to install affected versions
+	// (see errors.VulnerableVersionError)
+	Fail
+
+	// Skip makes LatestVersion skip affected versions.
+	// Sources installing an exact version treat it as Fail.
+	Skip
+)
+
+func (a Action) String() string {
+	switch a {
+	case Warn:
+		return "warn"
+	case Fail:
+		return "fail"
+	case Skip:
+		return "skip"
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// ParseAction parses name of an action (warn, fail or skip)
+func ParseAction(name string) (Action, error) {
+	for _, a := range []Action{Warn, Fail, Skip} {
+		if a.String() == name {
+			return a, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown advisory action %q", name)
+}
+
+// Database holds OSV records loaded via LoadDir, LoadZip or Fetch
+type Database struct {
+	records []Record
+}
+
+// Len returns the number of records
+func (db *Database) Len() int {
+	return len(db.records)
+}
+
+// Policy determines which advisories apply to a product
+// and how affected versions are treated
+type Policy struct {
+	Database *Database
+
+	// Packages overrides packages under which advisories of the product
+	// are recorded (defaults to the Go module in BuildInfo of the product)
+	Packages []Package
+
+	Action Action
+}
+
+// Validate checks that advisories can be matched against the product
+func (p *Policy) Validate(prod product.Product) error {
+	if p.Database == nil {
+		return fmt.Errorf("advisory database must be provided")
+	}
+	if p.Action < Warn || p.Action > Skip {
+		return fmt.Errorf("unknown advisory action: %s", p.Action)
+	}
+	if len(p.packages(prod)) == 0 {
+		return fmt.Errorf("no advisory packages known for %s", prod.Name)
+	}
+	return nil
+}
+
+func (p *Policy) packages(prod product.Product) []Package {
+	if len(p.Packages) > 0 {
+		return p.Packages
+	}
+	if prod.BuildInfo != nil && prod.BuildInfo.ModulePath != "" {
+		return []Package{{Ecosystem: "Go", Name: prod.BuildInfo.ModulePath}}
+	}
+	return nil
+}
+
+// Check returns advisories affecting the version of the product
+func (p *Policy) Check(prod product.Product, v *version.Version) []src.Advisory {
+	return p.Database.Check(p.packages(prod), v)
+}
+
+// Enforce returns errors.VulnerableVersionError if the version
+// is affected and the action doesn't allow installing it
+func (p *Policy) Enforce(prod product.Product, v *version.Version, advisories []src.Advisory) error {
+	if len(advisories) == 0 || p.Action == Warn {
+		return nil
+	}
+	ids := make([]string, 0, len(advisories))
+	for _, a := range advisories {
+		ids = append(ids, a.ID)
+	}
+	return &errors.VulnerableVersionError{
+		Product:     prod.Name,
+		Version:     v.String(),
+		AdvisoryIDs: ids,
+	}
+}
+
+// Check returns advisories affecting the version of any of the packages,
+// ordered by ID. Withdrawn advisories are ignored.
+func (db *Database) Check(pkgs []Package, v *version.Version) []src.Advisory {
+	advisories := make([]src.Advisory, 0)
+	for _, r := range db.records {
+		if r.Withdrawn != nil {
+			continue
+		}
+
+		affected := false
+		fixed := make([]string, 0)
+		for _, a := range r.Affected {
+			if !matchesPackage(a.Package, pkgs) {
+				continue
+			}
+			if a.affects(v) {
+				affected = true
+			}
+			fixed = append(fixed, a.fixedVersions()...)
+		}
+		if !affected {
+			continue
+		}
+
+		advisories = append(advisories, src.Advisory{
+			ID:            r.ID,
+			Aliases:       r.Aliases,
+			Summary:       r.Summary,
+			FixedVersions: fixed,
+		})
+	}
+
+	sort.Slice(advisories, func(i, j int) bool {
+		return advisories[i].ID < advisories[j].ID
+	})
+	return advisories
+}
+
+func matchesPackage(pkg Package, pkgs []Package) bool {
+	for _, p := range pkgs {
+		if strings.EqualFold(p.Ecosystem, pkg.Ecosystem) && p.Name == pkg.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// affects reports whether v is listed explicitly or within any range
+func (a Affected) affects(v *version.Version) bool {
+	for _, raw := range a.Versions {
+		if av, err := parseVersion(raw); err == nil && av.Equal(v) {
+			return true
+		}
+	}
+	for _, r := range a.Ranges {
+		if r.affects(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a Affected) fixedVersions() []string {
+	fixed := make([]string, 0)
+	for _, r := range a.Ranges {
+		for _, e := range r.Events {
+			if e.Fixed != "" {
+				fixed = append(fixed, strings.TrimPrefix(e.Fixed, "v"))
+			}
+		}
+	}
+	return fixed
+}
+
+type rangeEvent struct {
+	version *version.Version
+	kind    string
+}
+
+// affects evaluates events of the range in version order, such that
+// v is affected after an introduced event until a fixed event
+// at or below v, or a last_affected event below v
+func (r Range) affects(v *version.Version) bool {
+	if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+		return false
+	}
+
+	events := make([]rangeEvent, 0, len(r.Events))
+	for _, e := range r.Events {
+		var kind, raw string
+		switch {
+		case e.Introduced != "":
+			kind, raw = "introduced", e.Introduced
+		case e.Fixed != "":
+			kind, raw = "fixed", e.Fixed
+		case e.LastAffected != "":
+			kind, raw = "last_affected", e.LastAffected
+		default:
+			continue
+		}
+		if raw == "0" {
+			raw = "0.0.0-0"
+		}
+		ev, err := parseVersion(raw)
+		if err != nil {
+			continue
+		}
+		events = append(events, rangeEvent{version: ev, kind: kind})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].version.LessThan(events[j].version)
+	})
+
+	affected := false
+	for _, e := range events {
+		switch e.kind {
+		case "introduced":
+			if !e.version.GreaterThan(v) {
+				affected = true
+			}
+		case "fixed":
+			if !e.version.GreaterThan(v) {
+				affected = false
+			}
+		case "last_affected":
+			if e.version.LessThan(v) {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+func parseVersion(raw string) (*version.Version, error) {
+	return version.NewVersion(strings.TrimPrefix(raw, "v"))
+}
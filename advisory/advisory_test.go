@@ -0,0 +1,200 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package advisory
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lferrors "github.com/chushi-io/lf-install/errors"
+	"github.com/chushi-io/lf-install/internal/testutil"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/chushi-io/lf-install/src"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-version"
+)
+
+func advisoryIDs(advisories []src.Advisory) []string {
+	ids := make([]string, 0, len(advisories))
+	for _, a := range advisories {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+func TestPolicy_Check(t *testing.T) {
+	t.Parallel()
+
+	db, err := LoadDir(filepath.Join("testdata", "osv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 3 {
+		t.Fatalf("expected 3 records, got %d", db.Len())
+	}
+
+	testCases := map[string]struct {
+		product     product.Product
+		version     string
+		expectedIDs []string
+	}{
+		"old": {
+			product:     product.OpenTofu,
+			version:     "1.5.7",
+			expectedIDs: []string{"GO-2024-0001"},
+		},
+		"both": {
+			product:     product.OpenTofu,
+			version:     "1.6.0",
+			expectedIDs: []string{"GO-2024-0001", "GO-2024-0002"},
+		},
+		"fixed": {
+			product:     product.OpenTofu,
+			version:     "1.6.1",
+			expectedIDs: []string{"GO-2024-0002"},
+		},
+		"last-affected": {
+			product:     product.OpenTofu,
+			version:     "1.6.2",
+			expectedIDs: []string{"GO-2024-0002"},
+		},
+		"after-last-affected": {
+			product:     product.OpenTofu,
+			version:     "1.6.3",
+			expectedIDs: []string{},
+		},
+		"prerelease": {
+			product:     product.OpenTofu,
+			version:     "1.7.0-beta1",
+			expectedIDs: []string{"GO-2024-0001"},
+		},
+		"release-after-prerelease": {
+			product:     product.OpenTofu,
+			version:     "1.7.0",
+			expectedIDs: []string{},
+		},
+		"explicit-version": {
+			product:     product.OpenBao,
+			version:     "2.0.0",
+			expectedIDs: []string{"GO-2024-0002"},
+		},
+		"other-package": {
+			product:     product.OpenBao,
+			version:     "1.5.0",
+			expectedIDs: []string{},
+		},
+	}
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &Policy{Database: db}
+			advisories := p.Check(tc.product, version.Must(version.NewVersion(tc.version)))
+			if diff := cmp.Diff(tc.expectedIDs, advisoryIDs(advisories)); diff != "" {
+				t.Fatalf("unexpected advisories: %s", diff)
+			}
+		})
+	}
+}
+
+func TestPolicy_Enforce(t *testing.T) {
+	t.Parallel()
+
+	db, err := LoadDir(filepath.Join("testdata", "osv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := version.Must(version.NewVersion("1.6.0"))
+
+	warn := &Policy{Database: db, Action: Warn}
+	if err := warn.Enforce(product.OpenTofu, v, warn.Check(product.OpenTofu, v)); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	fail := &Policy{Database: db, Action: Fail}
+	err = fail.Enforce(product.OpenTofu, v, fail.Check(product.OpenTofu, v))
+	if !errors.Is(err, lferrors.ErrVulnerableVersion) {
+		t.Fatalf("expected vulnerable version error, got %v", err)
+	}
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	t.Parallel()
+
+	p := &Policy{Database: &Database{}}
+	err := p.Validate(product.Product{Name: "mytool"})
+	if err == nil {
+		t.Fatal("expected error for product without packages")
+	}
+
+	p.Packages = []Package{{Ecosystem: "Go", Name: "example.com/mytool"}}
+	if err := p.Validate(product.Product{Name: "mytool"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	b, err := os.ReadFile(filepath.Join("testdata", "osv", "GO-2024-0001.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := zw.Create("GO-2024-0001.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(b)
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/Go/all.zip", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buf.Bytes())
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	db, err := Fetch(context.Background(), testutil.TestLogger(), ts.URL+"/Go/all.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 1 {
+		t.Fatalf("expected 1 record, got %d", db.Len())
+	}
+}
+
+func TestReadAtMost(t *testing.T) {
+	t.Parallel()
+
+	b, err := readAtMost(strings.NewReader("12345"), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "12345" {
+		t.Fatalf("expected whole content to be read, given %q", b)
+	}
+
+	b, err = readAtMost(strings.NewReader("123456"), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b != nil {
+		t.Fatalf("expected content over the limit to be rejected, given %q", b)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package advisory
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chushi-io/lf-install/errors"
+	"github.com/chushi-io/lf-install/internal/httpclient"
+)
+
+// Record represents the subset of an OSV record
+// (https://ossf.github.io/osv-schema/) used for matching
+type Record struct {
+	ID        string     `json:"id"`
+	Aliases   []string   `json:"aliases,omitempty"`
+	Summary   string     `json:"summary,omitempty"`
+	Withdrawn *time.Time `json:"withdrawn,omitempty"`
+	Affected  []Affected `json:"affected,omitempty"`
+}
+
+type Affected struct {
+	Package  Package  `json:"package"`
+	Ranges   []Range  `json:"ranges,omitempty"`
+	Versions []string `json:"versions,omitempty"`
+}
+
+// Package identifies a package within an ecosystem,
+// e.g. Go module github.com/opentofu/opentofu
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+type Range struct {
+	// Type is one of SEMVER, ECOSYSTEM or GIT (GIT ranges are ignored)
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+// Event represents a single version boundary of a Range
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// maxZipSize limits size of downloaded archives
+const maxZipSize = 512 << 20
+
+// LoadDir loads OSV records from all JSON files in dir
+// (including subdirectories)
+func LoadDir(dir string) (*Database, error) {
+	db := &Database{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return db.add(path, b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// LoadZip loads OSV records from a ZIP archive, such as all.zip
+// published for each ecosystem by osv.dev
+func LoadZip(r io.ReaderAt, size int64) (*Database, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &Database{}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !strings.HasSuffix(f.Name, ".json") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		if err := db.add(f.Name, b); err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// Fetch downloads a ZIP archive of OSV records from a mirror, e.g.
+// https://osv-vulnerabilities.storage.googleapis.com/Go/all.zip
+func Fetch(ctx context.Context, logger *log.Logger, u string) (*Database, error) {
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+	client := httpclient.NewHTTPClient(logger)
+
+	logger.Printf("downloading advisories from %s", u)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %q: %w", u, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &errors.NetworkError{URL: u, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &errors.NetworkError{
+			URL:        u,
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("failed to download advisories from %q: %s", u, resp.Status),
+		}
+	}
+
+	b, err := readAtMost(resp.Body, maxZipSize)
+	if err != nil {
+		return nil, &errors.NetworkError{URL: u, Err: err}
+	}
+	if b == nil {
+		return nil, fmt.Errorf("advisory database at %q exceeds size limit of %d bytes", u, maxZipSize)
+	}
+
+	return LoadZip(bytes.NewReader(b), int64(len(b)))
+}
+
+// readAtMost reads r up to limit bytes, returning nil
+// (rather than truncated data) if there is more to read
+func readAtMost(r io.Reader, limit int64) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > limit {
+		return nil, nil
+	}
+	return b, nil
+}
+
+func (db *Database) add(name string, b []byte) error {
+	var r Record
+	if err := json.Unmarshal(b, &r); err != nil {
+		return fmt.Errorf("unable to parse OSV record %s: %w", name, err)
+	}
+	if r.ID == "" {
+		return fmt.Errorf("OSV record %s has no id", name)
+	}
+	db.records = append(db.records, r)
+	return nil
+}
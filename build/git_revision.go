@@ -12,10 +12,12 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/chushi-io/lf-install/errors"
+	"github.com/chushi-io/lf-install/internal/lockfile"
 	isrc "github.com/chushi-io/lf-install/internal/src"
 	"github.com/chushi-io/lf-install/internal/validators"
 	"github.com/chushi-io/lf-install/product"
-	"github.com/go-git/go-git/v5"
+	"github.com/chushi-io/lf-install/src"
 	"github.com/go-git/go-git/v5/plumbing"
 )
 
@@ -42,12 +44,24 @@ type GitRevision struct {
 	// If empty, license files will placed in the same directory as the binary.
 	LicenseDir string
 
-	Ref          string
+	// Ref represents the revision to build, i.e. a branch or tag name,
+	// full or abbreviated commit SHA, or a reference name
+	// (e.g. refs/pull/123/head). Defaults to HEAD of the repository.
+	Ref string
+
+	// MirrorDir represents directory where bare mirrors of repositories
+	// are kept and reused between builds (including concurrent ones
+	// in other processes), such that only new objects are fetched
+	// and tags already mirrored are built without contacting the remote.
+	// If empty, the revision is fetched from scratch.
+	MirrorDir string
+
 	CloneTimeout time.Duration
 	BuildTimeout time.Duration
 
 	logger        *log.Logger
 	pathsToRemove []string
+	details       src.Details
 }
 
 func (*GitRevision) IsSourceImpl() isrc.InstallSrcSigil {
@@ -87,30 +101,42 @@ func (gr *GitRevision) Validate() error {
 	return nil
 }
 
-func (gr *GitRevision) Build(ctx context.Context) (string, error) {
-	bi := gr.Product.BuildInstructions
+// runPreCloneCheck runs the PreCloneCheck of the product, if any
+func runPreCloneCheck(ctx context.Context, logger *log.Logger, p product.Product) error {
+	bi := p.BuildInstructions
+	if bi.PreCloneCheck == nil {
+		return nil
+	}
 
-	if bi.PreCloneCheck != nil {
-		preCloneCheckTimeout := defaultPreCloneCheckTimeout
-		if bi.PreCloneCheckTimeout > 0 {
-			preCloneCheckTimeout = bi.PreCloneCheckTimeout
-		}
+	preCloneCheckTimeout := defaultPreCloneCheckTimeout
+	if bi.PreCloneCheckTimeout > 0 {
+		preCloneCheckTimeout = bi.PreCloneCheckTimeout
+	}
 
-		pccCtx, cancelFunc := context.WithTimeout(ctx, preCloneCheckTimeout)
-		defer cancelFunc()
+	pccCtx, cancelFunc := context.WithTimeout(ctx, preCloneCheckTimeout)
+	defer cancelFunc()
 
-		gr.log().Printf("running %s pre-clone check (timeout: %s)",
-			gr.Product.Name, preCloneCheckTimeout)
-		err := bi.PreCloneCheck.Check(pccCtx)
-		if err != nil {
-			return "", err
-		}
-		gr.log().Printf("%s pre-clone check finished", gr.Product.Name)
+	logger.Printf("running %s pre-clone check (timeout: %s)",
+		p.Name, preCloneCheckTimeout)
+	err := bi.PreCloneCheck.Check(pccCtx)
+	if err != nil {
+		return err
+	}
+	logger.Printf("%s pre-clone check finished", p.Name)
+	return nil
+}
+
+func (gr *GitRevision) Build(ctx context.Context) (string, error) {
+	bi := gr.Product.BuildInstructions
+
+	if err := runPreCloneCheck(ctx, gr.log(), gr.Product); err != nil {
+		return "", err
 	}
 
 	if gr.pathsToRemove == nil {
 		gr.pathsToRemove = make([]string, 0)
 	}
+	firstPathToRemove := len(gr.pathsToRemove)
 
 	repoDir, err := os.MkdirTemp("",
 		fmt.Sprintf("lf-install-build-%s", gr.Product.Name))
@@ -138,22 +164,14 @@ func (gr *GitRevision) Build(ctx context.Context) (string, error) {
 		gr.Product.Name,
 		gr.Product.BuildInstructions.GitRepoURL,
 		repoDir, cloneTimeout)
-	repo, err := git.PlainCloneContext(cloneCtx, repoDir, false, &git.CloneOptions{
-		URL:           gr.Product.BuildInstructions.GitRepoURL,
-		ReferenceName: plumbing.ReferenceName(gr.Ref),
-		Depth:         1,
-	})
+	head, err := gr.checkout(cloneCtx, repoDir)
 	if err != nil {
 		return "", fmt.Errorf("unable to clone %s from %q @ %q: %w",
 			gr.Product.Name, gr.Product.BuildInstructions.GitRepoURL, ref, err)
 	}
 	gr.log().Printf("cloning %s finished", gr.Product.Name)
-	head, err := repo.Head()
-	if err != nil {
-		return "", err
-	}
 
-	gr.log().Printf("%s repository HEAD is at %s", gr.Product.Name, head.Hash())
+	gr.log().Printf("%s repository HEAD is at %s", gr.Product.Name, head)
 
 	buildTimeout := defaultBuildTimeout
 	if bi.BuildTimeout > 0 {
@@ -172,7 +190,7 @@ func (gr *GitRevision) Build(ctx context.Context) (string, error) {
 	installDir := gr.InstallDir
 	if installDir == "" {
 		tmpDir, err := os.MkdirTemp("",
-			fmt.Sprintf("lf-install-%s-%s", gr.Product.Name, head.Hash()))
+			fmt.Sprintf("lf-install-%s-%s", gr.Product.Name, head))
 		if err != nil {
 			return "", err
 		}
@@ -188,34 +206,119 @@ func (gr *GitRevision) Build(ctx context.Context) (string, error) {
 		licenseDir = installDir
 	}
 	gr.log().Printf("attempting to copy license file to %q", licenseDir)
-	if err := gr.copyLicenseIfExists(repoDir, licenseDir); err != nil {
+	licensePaths, err := copyLicenseIfExists(gr.log(), repoDir, licenseDir)
+	if err != nil {
 		return "", err
 	}
+	gr.pathsToRemove = append(gr.pathsToRemove, licensePaths...)
 
 	gr.log().Printf("building %s (timeout: %s)", gr.Product.Name, buildTimeout)
 	defer gr.log().Printf("building of %s finished", gr.Product.Name)
-	return bi.Build.Build(buildCtx, repoDir, installDir, gr.Product.BinaryName())
+	execPath, err := bi.Build.Build(buildCtx, repoDir, installDir, gr.Product.BinaryName())
+	if err != nil {
+		return "", &errors.BuildError{Product: gr.Product.Name, Err: err}
+	}
+
+	files := append([]string{}, gr.pathsToRemove[firstPathToRemove:]...)
+	gr.details = src.Details{
+		Files:    append(files, execPath),
+		Revision: head.String(),
+	}
+
+	return execPath, nil
 }
 
-func (gr *GitRevision) copyLicenseIfExists(repoDir string, dstDir string) error {
+// checkout checks out Ref into repoDir, either from the mirror
+// in MirrorDir or straight from the repository
+func (gr *GitRevision) checkout(ctx context.Context, repoDir string) (plumbing.Hash, error) {
+	url := gr.Product.BuildInstructions.GitRepoURL
+	if gr.MirrorDir != "" {
+		return gr.checkoutFromMirror(ctx, repoDir, url)
+	}
+
+	target, err := resolveGitTarget(ctx, url, gr.Ref)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	gr.log().Printf("resolved %q to %s", gr.Ref, target)
+
+	repo, h, err := fetchShallow(ctx, gr.log(), repoDir, url, target)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return h, checkoutCommit(repo, h)
+}
+
+// checkoutFromMirror updates the mirror of url (unless Ref is a tag
+// already mirrored) while holding a lock shared with other processes
+// and checks out Ref from it into repoDir
+func (gr *GitRevision) checkoutFromMirror(ctx context.Context, repoDir, url string) (plumbing.Hash, error) {
+	path := mirrorPath(gr.MirrorDir, url)
+	lock, err := lockfile.Acquire(ctx, path+".lock")
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("unable to lock mirror at %s: %w", path, err)
+	}
+	defer lock.Release()
+
+	mirror, err := openMirror(gr.log(), path, url)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	var h plumbing.Hash
+	if target, ok := mirroredTag(mirror, gr.Ref); ok {
+		gr.log().Printf("found %s in mirror at %s", target, path)
+		h, err = target.commitHash(mirror, target.name)
+	} else {
+		target, err = resolveGitTarget(ctx, url, gr.Ref)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		gr.log().Printf("resolved %q to %s", gr.Ref, target)
+		h, err = fetchMirror(ctx, gr.log(), mirror, url, target)
+	}
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	repo, err := exportCommit(mirror, repoDir, h)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return h, checkoutCommit(repo, h)
+}
+
+// Details describes the binary built by the last Build call
+func (gr *GitRevision) Details() src.Details {
+	details := gr.details
+	details.Product = gr.Product.Name
+	return details
+}
+
+// copyLicenseIfExists copies license file of the repository to dstDir
+// and returns paths of the copied files
+func copyLicenseIfExists(logger *log.Logger, repoDir string, dstDir string) ([]string, error) {
 	licenseFiles := []string{"LICENSE.txt", "LICENSE"}
+	copied := make([]string, 0)
 
 	for _, file := range licenseFiles {
 		srcPath := filepath.Join(repoDir, file)
 		if _, err := os.Stat(srcPath); err == nil {
-			gr.log().Printf("found license file at %q", srcPath)
+			logger.Printf("found license file at %q", srcPath)
 			dstPath := filepath.Join(dstDir, dstLicenseFileName)
-			if err := gr.copyLicenseFile(srcPath, dstPath); err != nil {
-				return fmt.Errorf("failed to copy license file from %q to %q: %w", srcPath, dstPath, err)
+			if err := copyLicenseFile(logger, srcPath, dstPath); err != nil {
+				return copied, fmt.Errorf("failed to copy license file from %q to %q: %w", srcPath, dstPath, err)
 			}
+			// Add the license file to the list of paths to remove after being successfully copied
+			copied = append(copied, dstPath)
 		}
 	}
 
-	return nil
+	return copied, nil
 }
 
-func (gr *GitRevision) copyLicenseFile(srcPath, dstPath string) error {
-	gr.log().Printf("copying license file from %q to %q", srcPath, dstPath)
+func copyLicenseFile(logger *log.Logger, srcPath, dstPath string) error {
+	logger.Printf("copying license file from %q to %q", srcPath, dstPath)
 	src, err := os.Open(srcPath)
 	if err != nil {
 		return fmt.Errorf("failed to open license file at %q: %w", srcPath, err)
@@ -231,10 +334,8 @@ func (gr *GitRevision) copyLicenseFile(srcPath, dstPath string) error {
 	if err != nil {
 		return fmt.Errorf("failed to copy license file from %q to %q: %w", srcPath, dstPath, err)
 	}
-	gr.log().Printf("license file copied from %q to %q (%d bytes)",
+	logger.Printf("license file copied from %q to %q (%d bytes)",
 		srcPath, dstPath, n)
-	// Add the license file to the list of paths to remove after being successfully copied
-	gr.pathsToRemove = append(gr.pathsToRemove, dstPath)
 	return nil
 }
 
@@ -20,6 +20,7 @@ var (
 	_ src.Buildable      = &GitRevision{}
 	_ src.Removable      = &GitRevision{}
 	_ src.LoggerSettable = &GitRevision{}
+	_ src.Describable    = &GitRevision{}
 )
 
 func TestGitRevision_tofu(t *testing.T) {
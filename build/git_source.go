@@ -0,0 +1,364 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package build
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// buildRefName is where the ref to build is fetched to
+// in a repository cloned for a single build
+const buildRefName = plumbing.ReferenceName("refs/lf-install/build")
+
+// gitTarget represents what Ref of GitRevision resolved to,
+// i.e. either a reference, a commit or a commit prefix
+type gitTarget struct {
+	ref string
+
+	name   plumbing.ReferenceName
+	hash   plumbing.Hash
+	prefix string
+}
+
+func (t gitTarget) String() string {
+	if t.name != "" && string(t.name) != t.ref {
+		return fmt.Sprintf("%s (%s)", t.ref, t.name)
+	}
+	return t.ref
+}
+
+var abbreviatedHash = regexp.MustCompile(`^[0-9a-f]{4,39}$`)
+
+// resolveGitTarget determines what to fetch for the given ref, which is one of:
+//
+//   - empty or HEAD, i.e. the default branch
+//   - full commit SHA
+//   - full reference name (e.g. refs/tags/v1.6.2 or refs/pull/123/head)
+//   - pull request ref (e.g. pull/123/head)
+//   - tag or branch name (e.g. v1.6.2 or main), tags taking precedence
+//   - abbreviated commit SHA (at least 4 characters)
+//
+// The remote is only listed when needed to tell these apart.
+func resolveGitTarget(ctx context.Context, url, ref string) (gitTarget, error) {
+	t := gitTarget{ref: ref}
+	switch {
+	case plumbing.IsHash(ref):
+		t.hash = plumbing.NewHash(ref)
+		return t, nil
+	case strings.HasPrefix(ref, "refs/"):
+		t.name = plumbing.ReferenceName(ref)
+		return t, nil
+	case strings.HasPrefix(ref, "pull/"):
+		t.name = plumbing.ReferenceName("refs/" + ref)
+		return t, nil
+	}
+	if t.ref == "" {
+		t.ref = "HEAD"
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{url},
+	})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{})
+	if err != nil {
+		return t, fmt.Errorf("unable to list references of %q: %w", url, err)
+	}
+	remoteRefs := make(map[plumbing.ReferenceName]*plumbing.Reference, len(refs))
+	for _, r := range refs {
+		remoteRefs[r.Name()] = r
+	}
+
+	if t.ref == "HEAD" {
+		head, ok := remoteRefs[plumbing.HEAD]
+		if !ok {
+			return t, fmt.Errorf("%q has no HEAD", url)
+		}
+		if head.Type() == plumbing.SymbolicReference {
+			t.name = head.Target()
+		} else {
+			t.hash = head.Hash()
+		}
+		return t, nil
+	}
+
+	for _, name := range []plumbing.ReferenceName{
+		plumbing.NewTagReferenceName(ref),
+		plumbing.NewBranchReferenceName(ref),
+	} {
+		if _, ok := remoteRefs[name]; ok {
+			t.name = name
+			return t, nil
+		}
+	}
+
+	if abbreviatedHash.MatchString(ref) {
+		t.prefix = ref
+		return t, nil
+	}
+
+	return t, fmt.Errorf("no tag, branch or commit %q found in %q", ref, url)
+}
+
+// commitHash resolves the target in the repository to a commit,
+// peeling annotated tags
+func (t gitTarget) commitHash(repo *git.Repository, fetchedName plumbing.ReferenceName) (plumbing.Hash, error) {
+	var h plumbing.Hash
+	switch {
+	case fetchedName != "":
+		ref, err := repo.Reference(fetchedName, true)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("unable to resolve %s: %w", t, err)
+		}
+		h = ref.Hash()
+	case !t.hash.IsZero():
+		h = t.hash
+	default:
+		resolved, err := repo.ResolveRevision(plumbing.Revision(t.prefix))
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("unable to resolve commit %q: %w", t.prefix, err)
+		}
+		h = *resolved
+	}
+
+	if tag, err := repo.TagObject(h); err == nil {
+		c, err := tag.Commit()
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("unable to resolve tag %s: %w", t, err)
+		}
+		return c.Hash, nil
+	}
+	c, err := repo.CommitObject(h)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("commit %s of %s not found: %w", h, t, err)
+	}
+	return c.Hash, nil
+}
+
+// headsAndTags represents refspecs fetching all branches and tags
+var headsAndTags = []config.RefSpec{
+	"+refs/heads/*:refs/heads/*",
+	"+refs/tags/*:refs/tags/*",
+}
+
+// fetch ignores NoErrAlreadyUpToDate, which is not a failure
+func fetch(ctx context.Context, repo *git.Repository, opts *git.FetchOptions) error {
+	err := repo.FetchContext(ctx, opts)
+	if err != nil && !stderrors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+	return nil
+}
+
+// fetchShallow fetches the target from url into a new repository at dir
+// with depth of 1 where possible, returning the commit to build
+func fetchShallow(ctx context.Context, logger *log.Logger, dir, url string, t gitTarget) (*git.Repository, plumbing.Hash, error) {
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{url},
+	})
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+
+	var fetchedName plumbing.ReferenceName
+	switch {
+	case t.name != "":
+		fetchedName = buildRefName
+		err = fetch(ctx, repo, &git.FetchOptions{
+			RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", t.name, buildRefName))},
+			Depth:    1,
+			Tags:     git.NoTags,
+		})
+	case !t.hash.IsZero():
+		err = fetch(ctx, repo, &git.FetchOptions{
+			RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("%s:%s", t.hash, buildRefName))},
+			Depth:    1,
+			Tags:     git.NoTags,
+		})
+		if stderrors.Is(err, git.ErrExactSHA1NotSupported) {
+			logger.Printf("%s does not support fetching commits, fetching all branches and tags", url)
+			err = fetch(ctx, repo, &git.FetchOptions{RefSpecs: headsAndTags, Tags: git.NoTags})
+		}
+	default:
+		// abbreviated commits can only be resolved locally
+		logger.Printf("fetching all branches and tags to resolve %q", t.prefix)
+		err = fetch(ctx, repo, &git.FetchOptions{RefSpecs: headsAndTags, Tags: git.NoTags})
+	}
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+
+	h, err := t.commitHash(repo, fetchedName)
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+	return repo, h, nil
+}
+
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// mirrorPath returns path of the mirror of the repository at url within dir
+// (e.g. github.com_opentofu_opentofu.git)
+func mirrorPath(dir, url string) string {
+	name := url
+	if _, rest, ok := strings.Cut(name, "://"); ok {
+		name = rest
+	}
+	name = strings.TrimSuffix(strings.TrimSuffix(name, "/"), ".git")
+	name = unsafePathChars.ReplaceAllString(name, "_")
+	return filepath.Join(dir, name+".git")
+}
+
+// openMirror opens the bare mirror of url at path, creating it if needed
+func openMirror(logger *log.Logger, path, url string) (*git.Repository, error) {
+	repo, err := git.PlainOpen(path)
+	if stderrors.Is(err, git.ErrRepositoryNotExists) {
+		logger.Printf("creating mirror of %s at %s", url, path)
+		repo, err = git.PlainInit(path, true)
+		if err == nil {
+			_, err = repo.CreateRemote(&config.RemoteConfig{
+				Name: git.DefaultRemoteName,
+				URLs: []string{url},
+			})
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to open mirror at %s: %w", path, err)
+	}
+	return repo, nil
+}
+
+// mirroredTag resolves ref to a tag already present in the mirror.
+// Tags (unlike branches) aren't expected to move, so such
+// a target can be used without contacting the remote.
+func mirroredTag(repo *git.Repository, ref string) (gitTarget, bool) {
+	t := gitTarget{ref: ref}
+	switch {
+	case ref == "", ref == "HEAD", plumbing.IsHash(ref), strings.HasPrefix(ref, "pull/"):
+		return t, false
+	case strings.HasPrefix(ref, "refs/"):
+		t.name = plumbing.ReferenceName(ref)
+	default:
+		t.name = plumbing.NewTagReferenceName(ref)
+	}
+	if !t.name.IsTag() {
+		return t, false
+	}
+	if _, err := repo.Reference(t.name, false); err != nil {
+		return t, false
+	}
+	return t, true
+}
+
+// fetchMirror fetches all branches, tags and the target (if it's outside
+// of those) from url into the mirror. Only objects missing
+// in the mirror are transferred.
+func fetchMirror(ctx context.Context, logger *log.Logger, repo *git.Repository, url string, t gitTarget) (plumbing.Hash, error) {
+	refSpecs := append([]config.RefSpec{}, headsAndTags...)
+	if t.name != "" && !t.name.IsBranch() && !t.name.IsTag() {
+		refSpecs = append(refSpecs, config.RefSpec(fmt.Sprintf("+%s:%s", t.name, t.name)))
+	}
+	logger.Printf("updating mirror of %s", url)
+	err := fetch(ctx, repo, &git.FetchOptions{RefSpecs: refSpecs, Tags: git.NoTags, Prune: true})
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("unable to update mirror of %s: %w", url, err)
+	}
+
+	// commits not reachable from any branch or tag (e.g. of closed
+	// pull requests) may still be fetched directly, if the server allows it
+	if !t.hash.IsZero() {
+		if _, err := repo.CommitObject(t.hash); err != nil {
+			logger.Printf("fetching commit %s", t.hash)
+			err = fetch(ctx, repo, &git.FetchOptions{
+				RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("%s:refs/lf-install/%s", t.hash, t.hash))},
+				Tags:     git.NoTags,
+			})
+			if err != nil {
+				return plumbing.ZeroHash, fmt.Errorf("unable to fetch commit %s: %w", t.hash, err)
+			}
+		}
+	}
+
+	return t.commitHash(repo, t.name)
+}
+
+// exportCommit creates a repository at dir with the tree of the commit
+// from the mirror checked out, copying only objects of that commit
+// (i.e. as if it was cloned with depth of 1)
+func exportCommit(mirror *git.Repository, dir string, h plumbing.Hash) (*git.Repository, error) {
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := mirror.CommitObject(h)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := []plumbing.Hash{commit.Hash, tree.Hash}
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		_, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// submodules are not part of the repository
+		if entry.Mode == filemode.Submodule {
+			continue
+		}
+		hashes = append(hashes, entry.Hash)
+	}
+
+	for _, oh := range hashes {
+		obj, err := mirror.Storer.EncodedObject(plumbing.AnyObject, oh)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := repo.Storer.SetEncodedObject(obj); err != nil {
+			return nil, err
+		}
+	}
+	if err := repo.Storer.SetShallow([]plumbing.Hash{h}); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// checkoutCommit checks out the commit into the worktree of the repository
+func checkoutCommit(repo *git.Repository, h plumbing.Hash) error {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	return wt.Checkout(&git.CheckoutOptions{Hash: h, Force: true})
+}
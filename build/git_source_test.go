@@ -0,0 +1,331 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package build
+
+import (
+	"context"
+	stderrors "errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chushi-io/lf-install/internal/lockfile"
+	"github.com/chushi-io/lf-install/internal/testutil"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// copyBuilder "builds" the product by copying the VERSION file
+// of the checked out repository to the binary path
+type copyBuilder struct{}
+
+func (*copyBuilder) Build(ctx context.Context, repoDir, targetDir, binaryName string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(repoDir, "VERSION"))
+	if err != nil {
+		return "", err
+	}
+	execPath := filepath.Join(targetDir, binaryName)
+	return execPath, os.WriteFile(execPath, b, 0o755)
+}
+
+func (*copyBuilder) Remove(ctx context.Context) error {
+	return nil
+}
+
+// testOrigin represents a local repository to build from
+type testOrigin struct {
+	t    *testing.T
+	dir  string
+	repo *git.Repository
+}
+
+func newTestOrigin(t *testing.T) *testOrigin {
+	t.Helper()
+
+	// the file transport of go-git relies on git binaries
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testOrigin{t: t, dir: dir, repo: repo}
+}
+
+func (o *testOrigin) commit(v string) plumbing.Hash {
+	o.t.Helper()
+
+	if err := os.WriteFile(filepath.Join(o.dir, "VERSION"), []byte(v), 0o644); err != nil {
+		o.t.Fatal(err)
+	}
+	wt, err := o.repo.Worktree()
+	if err != nil {
+		o.t.Fatal(err)
+	}
+	if _, err := wt.Add("VERSION"); err != nil {
+		o.t.Fatal(err)
+	}
+	h, err := wt.Commit(v, &git.CommitOptions{Author: o.signature()})
+	if err != nil {
+		o.t.Fatal(err)
+	}
+	return h
+}
+
+func (o *testOrigin) signature() *object.Signature {
+	return &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+}
+
+func (o *testOrigin) setRef(name string, h plumbing.Hash) {
+	o.t.Helper()
+
+	err := o.repo.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName(name), h))
+	if err != nil {
+		o.t.Fatal(err)
+	}
+}
+
+func (o *testOrigin) product() product.Product {
+	return product.Product{
+		Name:       "test",
+		BinaryName: func() string { return "test" },
+		BuildInstructions: &product.BuildInstructions{
+			GitRepoURL: o.dir,
+			Build:      &copyBuilder{},
+		},
+	}
+}
+
+func TestGitRevision_Build_refs(t *testing.T) {
+	t.Parallel()
+
+	origin := newTestOrigin(t)
+	v1 := origin.commit("1.0.0")
+	origin.setRef("refs/tags/v1.0.0", v1)
+	v2 := origin.commit("2.0.0")
+	_, err := origin.repo.CreateTag("v2.0.0", v2, &git.CreateTagOptions{
+		Tagger:  origin.signature(),
+		Message: "2.0.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr := origin.commit("pull-request")
+	origin.setRef("refs/pull/1/head", pr)
+	// leave the pull request commit reachable only via its ref
+	origin.setRef("refs/heads/master", v2)
+	head := origin.commit("3.0.0-dev")
+
+	testCases := map[string]struct {
+		ref              string
+		expectedVersion  string
+		expectedRevision plumbing.Hash
+	}{
+		"default":        {"", "3.0.0-dev", head},
+		"commit":         {v1.String(), "1.0.0", v1},
+		"short-commit":   {v2.String()[:7], "2.0.0", v2},
+		"tag":            {"v1.0.0", "1.0.0", v1},
+		"annotated-tag":  {"v2.0.0", "2.0.0", v2},
+		"full-tag":       {"refs/tags/v2.0.0", "2.0.0", v2},
+		"pull-request":   {"pull/1/head", "pull-request", pr},
+		"pull-full-ref":  {"refs/pull/1/head", "pull-request", pr},
+		"default-branch": {"master", "3.0.0-dev", head},
+	}
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+		for _, mirror := range []bool{false, true} {
+			mirror := mirror
+			testName := name
+			if mirror {
+				testName += "-mirror"
+			}
+			t.Run(testName, func(t *testing.T) {
+				t.Parallel()
+
+				gr := &GitRevision{
+					Product:    origin.product(),
+					InstallDir: t.TempDir(),
+					Ref:        tc.ref,
+				}
+				if mirror {
+					gr.MirrorDir = t.TempDir()
+				}
+				gr.SetLogger(testutil.TestLogger())
+
+				ctx := context.Background()
+				execPath, err := gr.Build(ctx)
+				t.Cleanup(func() { gr.Remove(ctx) })
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				b, err := os.ReadFile(execPath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(b) != tc.expectedVersion {
+					t.Fatalf("expected %q to be built, given %q", tc.expectedVersion, string(b))
+				}
+				if rev := gr.Details().Revision; rev != tc.expectedRevision.String() {
+					t.Fatalf("expected revision %s, given %s", tc.expectedRevision, rev)
+				}
+			})
+		}
+	}
+}
+
+func TestGitRevision_Build_mirrorReused(t *testing.T) {
+	t.Parallel()
+
+	origin := newTestOrigin(t)
+	origin.commit("1.0.0")
+	mirrorDir := t.TempDir()
+
+	build := func(expectedVersion string) {
+		t.Helper()
+
+		gr := &GitRevision{
+			Product:    origin.product(),
+			InstallDir: t.TempDir(),
+			MirrorDir:  mirrorDir,
+		}
+		gr.SetLogger(testutil.TestLogger())
+
+		ctx := context.Background()
+		execPath, err := gr.Build(ctx)
+		t.Cleanup(func() { gr.Remove(ctx) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(execPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != expectedVersion {
+			t.Fatalf("expected %q to be built, given %q", expectedVersion, string(b))
+		}
+	}
+
+	build("1.0.0")
+	origin.commit("1.1.0")
+	build("1.1.0")
+
+	entries, err := os.ReadDir(mirrorDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the mirror along with its lock file
+	if len(entries) != 2 {
+		t.Fatalf("expected a single mirror, given %d entries", len(entries))
+	}
+	mirror := mirrorPath(mirrorDir, origin.dir)
+	if _, err := os.Stat(mirror + ".lock"); err != nil {
+		t.Fatal(err)
+	}
+	// the mirror is kept after removal
+	if _, err := git.PlainOpen(mirror); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitRevision_Build_mirroredTag(t *testing.T) {
+	t.Parallel()
+
+	origin := newTestOrigin(t)
+	origin.setRef("refs/tags/v1.0.0", origin.commit("1.0.0"))
+	mirrorDir := t.TempDir()
+	p := origin.product()
+
+	build := func() error {
+		gr := &GitRevision{
+			Product:    p,
+			Ref:        "v1.0.0",
+			InstallDir: t.TempDir(),
+			MirrorDir:  mirrorDir,
+		}
+		gr.SetLogger(testutil.TestLogger())
+
+		ctx := context.Background()
+		_, err := gr.Build(ctx)
+		t.Cleanup(func() { gr.Remove(ctx) })
+		return err
+	}
+
+	if err := build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// tags already mirrored are built without contacting the remote
+	if err := os.RemoveAll(origin.dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := build(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitRevision_Build_mirrorLocked(t *testing.T) {
+	t.Parallel()
+
+	origin := newTestOrigin(t)
+	origin.commit("1.0.0")
+	mirrorDir := t.TempDir()
+
+	// another process is using the mirror
+	lock, err := lockfile.Acquire(context.Background(), mirrorPath(mirrorDir, origin.dir)+".lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Release()
+
+	gr := &GitRevision{
+		Product:    origin.product(),
+		InstallDir: t.TempDir(),
+		MirrorDir:  mirrorDir,
+	}
+	gr.SetLogger(testutil.TestLogger())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = gr.Build(ctx)
+	t.Cleanup(func() { gr.Remove(context.Background()) })
+	if !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected build to wait for the mirror lock, given %v", err)
+	}
+}
+
+func TestResolveGitTarget_unknownRef(t *testing.T) {
+	t.Parallel()
+
+	origin := newTestOrigin(t)
+	origin.commit("1.0.0")
+
+	_, err := resolveGitTarget(context.Background(), origin.dir, "no-such-branch")
+	if err == nil {
+		t.Fatal("expected error for unknown ref")
+	}
+}
+
+func TestMirrorPath(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"https://github.com/opentofu/opentofu.git": "github.com_opentofu_opentofu.git",
+		"https://github.com/opentofu/opentofu/":    "github.com_opentofu_opentofu.git",
+		"git@github.com:opentofu/opentofu.git":     "git_github.com_opentofu_opentofu.git",
+	}
+	for url, expected := range testCases {
+		if given := mirrorPath("mirrors", url); given != filepath.Join("mirrors", expected) {
+			t.Errorf("%s: expected %q, given %q", url, expected, given)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package build
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/chushi-io/lf-install/errors"
+	isrc "github.com/chushi-io/lf-install/internal/src"
+	"github.com/chushi-io/lf-install/internal/validators"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/chushi-io/lf-install/src"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// LocalCheckout installs the product by building source code
+// from a local directory per product BuildInstructions,
+// e.g. a working tree with patches under development.
+// The directory is built in place, without cloning.
+type LocalCheckout struct {
+	Product product.Product
+
+	// Dir represents path to the source code to build
+	Dir string
+
+	// RequireCleanWorktree requires Dir to be a git repository
+	// without any uncommitted changes or untracked files
+	RequireCleanWorktree bool
+
+	InstallDir string
+
+	// LicenseDir represents directory path where to install license files.
+	// If empty, license files will placed in the same directory as the binary.
+	LicenseDir string
+
+	BuildTimeout time.Duration
+
+	logger        *log.Logger
+	pathsToRemove []string
+	details       src.Details
+}
+
+func (*LocalCheckout) IsSourceImpl() isrc.InstallSrcSigil {
+	return isrc.InstallSrcSigil{}
+}
+
+func (lc *LocalCheckout) SetLogger(logger *log.Logger) {
+	lc.logger = logger
+}
+
+func (lc *LocalCheckout) log() *log.Logger {
+	if lc.logger == nil {
+		return discardLogger
+	}
+	return lc.logger
+}
+
+func (lc *LocalCheckout) Validate() error {
+	if !validators.IsProductNameValid(lc.Product.Name) {
+		return fmt.Errorf("invalid product name: %q", lc.Product.Name)
+	}
+	if !validators.IsBinaryNameValid(lc.Product.BinaryName()) {
+		return fmt.Errorf("invalid binary name: %q", lc.Product.BinaryName())
+	}
+
+	bi := lc.Product.BuildInstructions
+	if bi == nil {
+		return fmt.Errorf("no build instructions")
+	}
+	if bi.Build == nil {
+		return fmt.Errorf("missing build instructions")
+	}
+
+	if lc.Dir == "" {
+		return fmt.Errorf("missing source directory")
+	}
+	fi, err := os.Stat(lc.Dir)
+	if err != nil {
+		return fmt.Errorf("invalid source directory: %w", err)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("source %q is not a directory", lc.Dir)
+	}
+
+	return nil
+}
+
+func (lc *LocalCheckout) Build(ctx context.Context) (string, error) {
+	bi := lc.Product.BuildInstructions
+
+	revision, err := lc.revision()
+	if err != nil {
+		return "", err
+	}
+
+	if err := runPreCloneCheck(ctx, lc.log(), lc.Product); err != nil {
+		return "", err
+	}
+
+	if lc.pathsToRemove == nil {
+		lc.pathsToRemove = make([]string, 0)
+	}
+	firstPathToRemove := len(lc.pathsToRemove)
+
+	buildTimeout := defaultBuildTimeout
+	if bi.BuildTimeout > 0 {
+		buildTimeout = bi.BuildTimeout
+	}
+	if lc.BuildTimeout > 0 {
+		buildTimeout = lc.BuildTimeout
+	}
+
+	buildCtx, cancelFunc := context.WithTimeout(ctx, buildTimeout)
+	defer cancelFunc()
+
+	if loggableBuilder, ok := bi.Build.(withLogger); ok {
+		loggableBuilder.SetLogger(lc.log())
+	}
+	installDir := lc.InstallDir
+	if installDir == "" {
+		tmpDir, err := os.MkdirTemp("",
+			fmt.Sprintf("lf-install-%s-local", lc.Product.Name))
+		if err != nil {
+			return "", err
+		}
+		installDir = tmpDir
+		lc.pathsToRemove = append(lc.pathsToRemove, installDir)
+	}
+	lc.log().Printf("install dir is %q", installDir)
+
+	// copy license file on best effort basis
+	// default to installDir if LicenseDir is not set
+	licenseDir := lc.LicenseDir
+	if licenseDir == "" {
+		licenseDir = installDir
+	}
+	lc.log().Printf("attempting to copy license file to %q", licenseDir)
+	licensePaths, err := copyLicenseIfExists(lc.log(), lc.Dir, licenseDir)
+	if err != nil {
+		return "", err
+	}
+	lc.pathsToRemove = append(lc.pathsToRemove, licensePaths...)
+
+	lc.log().Printf("building %s from %s (timeout: %s)", lc.Product.Name, lc.Dir, buildTimeout)
+	defer lc.log().Printf("building of %s finished", lc.Product.Name)
+	execPath, err := bi.Build.Build(buildCtx, lc.Dir, installDir, lc.Product.BinaryName())
+	if err != nil {
+		return "", &errors.BuildError{Product: lc.Product.Name, Err: err}
+	}
+
+	files := append([]string{}, lc.pathsToRemove[firstPathToRemove:]...)
+	lc.details = src.Details{
+		Files:    append(files, execPath),
+		Revision: revision,
+	}
+
+	return execPath, nil
+}
+
+// revision returns the commit checked out in Dir if the worktree
+// is clean, or an empty string if it has changes or isn't a git repository.
+// An error is returned instead if RequireCleanWorktree is set.
+func (lc *LocalCheckout) revision() (string, error) {
+	repo, err := git.PlainOpenWithOptions(lc.Dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		if stderrors.Is(err, git.ErrRepositoryNotExists) && !lc.RequireCleanWorktree {
+			lc.log().Printf("%s is not a git repository", lc.Dir)
+			return "", nil
+		}
+		return "", fmt.Errorf("unable to open git repository at %q: %w", lc.Dir, err)
+	}
+
+	head, err := repo.Head()
+	if stderrors.Is(err, plumbing.ErrReferenceNotFound) && !lc.RequireCleanWorktree {
+		lc.log().Printf("%s has no commits", lc.Dir)
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to find HEAD of %q: %w", lc.Dir, err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return "", fmt.Errorf("unable to check status of %q: %w", lc.Dir, err)
+	}
+	if !status.IsClean() {
+		if lc.RequireCleanWorktree {
+			return "", fmt.Errorf("worktree at %q has uncommitted changes:\n%s", lc.Dir, status)
+		}
+		lc.log().Printf("%s has uncommitted changes on top of %s", lc.Dir, head.Hash())
+		return "", nil
+	}
+
+	lc.log().Printf("%s is at %s", lc.Dir, head.Hash())
+	return head.Hash().String(), nil
+}
+
+// Details describes the binary built by the last Build call
+func (lc *LocalCheckout) Details() src.Details {
+	details := lc.details
+	details.Product = lc.Product.Name
+	return details
+}
+
+// Remove removes any temporary install directory and copied
+// license files. The source directory is left intact.
+func (lc *LocalCheckout) Remove(ctx context.Context) error {
+	if lc.pathsToRemove != nil {
+		for _, path := range lc.pathsToRemove {
+			err := os.RemoveAll(path)
+			if err != nil {
+				return fmt.Errorf("failed to remove %q: %w", path, err)
+			}
+		}
+	}
+
+	return lc.Product.BuildInstructions.Build.Remove(ctx)
+}
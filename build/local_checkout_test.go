@@ -0,0 +1,190 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package build
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chushi-io/lf-install/internal/testutil"
+	"github.com/chushi-io/lf-install/src"
+)
+
+var (
+	_ src.Buildable      = &LocalCheckout{}
+	_ src.Removable      = &LocalCheckout{}
+	_ src.LoggerSettable = &LocalCheckout{}
+	_ src.Describable    = &LocalCheckout{}
+)
+
+func TestLocalCheckout_Build(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		dirty            bool
+		requireClean     bool
+		expectedVersion  string
+		expectedRevision bool
+		expectedErr      bool
+	}{
+		"clean": {
+			expectedVersion:  "1.0.0",
+			expectedRevision: true,
+		},
+		"clean-required": {
+			requireClean:     true,
+			expectedVersion:  "1.0.0",
+			expectedRevision: true,
+		},
+		"dirty": {
+			dirty:           true,
+			expectedVersion: "1.1.0-dev",
+		},
+		"dirty-clean-required": {
+			dirty:        true,
+			requireClean: true,
+			expectedErr:  true,
+		},
+	}
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			origin := newTestOrigin(t)
+			h := origin.commit("1.0.0")
+			if tc.dirty {
+				err := os.WriteFile(filepath.Join(origin.dir, "VERSION"), []byte("1.1.0-dev"), 0o644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			lc := &LocalCheckout{
+				Product:              origin.product(),
+				Dir:                  origin.dir,
+				RequireCleanWorktree: tc.requireClean,
+				InstallDir:           t.TempDir(),
+			}
+			lc.SetLogger(testutil.TestLogger())
+			if err := lc.Validate(); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+			execPath, err := lc.Build(ctx)
+			t.Cleanup(func() { lc.Remove(ctx) })
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatal("expected error for dirty worktree")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := os.ReadFile(execPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tc.expectedVersion {
+				t.Fatalf("expected %q to be built, given %q", tc.expectedVersion, string(b))
+			}
+
+			expectedRevision := ""
+			if tc.expectedRevision {
+				expectedRevision = h.String()
+			}
+			if rev := lc.Details().Revision; rev != expectedRevision {
+				t.Fatalf("expected revision %q, given %q", expectedRevision, rev)
+			}
+		})
+	}
+}
+
+func TestLocalCheckout_Build_notRepository(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"VERSION": "1.0.0",
+		"LICENSE": "license text",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := (&testOrigin{dir: dir}).product()
+	licenseDir := t.TempDir()
+
+	lc := &LocalCheckout{
+		Product:    p,
+		Dir:        dir,
+		LicenseDir: licenseDir,
+	}
+	lc.SetLogger(testutil.TestLogger())
+
+	ctx := context.Background()
+	execPath, err := lc.Build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	licensePath := filepath.Join(licenseDir, dstLicenseFileName)
+	if _, err := os.Stat(licensePath); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := lc.Remove(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{execPath, licensePath} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("expected %q to be removed, given %v", path, err)
+		}
+	}
+	// the source is left intact
+	if _, err := os.Stat(filepath.Join(dir, "VERSION")); err != nil {
+		t.Fatal(err)
+	}
+
+	lc.RequireCleanWorktree = true
+	if _, err := lc.Build(ctx); err == nil {
+		t.Fatal("expected error for clean worktree required outside of repository")
+	}
+}
+
+func TestLocalCheckout_Validate(t *testing.T) {
+	t.Parallel()
+
+	p := (&testOrigin{dir: "unused"}).product()
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		dir         string
+		expectedErr bool
+	}{
+		"directory": {dir: t.TempDir()},
+		"missing":   {dir: "", expectedErr: true},
+		"not-found": {dir: filepath.Join(t.TempDir(), "none"), expectedErr: true},
+		"file":      {dir: file, expectedErr: true},
+	}
+
+	for name, tc := range testCases {
+		lc := &LocalCheckout{Product: p, Dir: tc.dir}
+		err := lc.Validate()
+		if tc.expectedErr && err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+		if !tc.expectedErr && err != nil {
+			t.Errorf("%s: unexpected error: %s", name, err)
+		}
+	}
+}
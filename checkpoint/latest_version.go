@@ -10,7 +10,6 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"runtime"
 	"time"
 
 	"github.com/chushi-io/lf-install/internal/pubkey"
@@ -18,8 +17,7 @@ import (
 	isrc "github.com/chushi-io/lf-install/internal/src"
 	"github.com/chushi-io/lf-install/internal/validators"
 	"github.com/chushi-io/lf-install/product"
-	checkpoint "github.com/hashicorp/go-checkpoint"
-	"github.com/hashicorp/go-version"
+	"github.com/chushi-io/lf-install/src"
 )
 
 var (
@@ -27,7 +25,7 @@ var (
 	discardLogger  = log.New(io.Discard, "", 0)
 )
 
-// LatestVersion installs the latest version known to Checkpoint
+// LatestVersion installs the latest stable version determined by Resolver
 // to OS temp directory, or to InstallDir (if not empty)
 type LatestVersion struct {
 	Product                  product.Product
@@ -35,6 +33,14 @@ type LatestVersion struct {
 	SkipChecksumVerification bool
 	InstallDir               string
 
+	// Resolver determines the latest version, defaults to the highest
+	// stable version in the release index (see ReleaseIndexResolver)
+	Resolver LatestResolver
+
+	// ApiBaseURL overrides URL of the releases API the product is downloaded
+	// from (and the default Resolver reads the release index from)
+	ApiBaseURL string
+
 	// LicenseDir represents directory path where to install license files.
 	// If empty, license files will placed in the same directory as the binary.
 	LicenseDir string
@@ -45,6 +51,12 @@ type LatestVersion struct {
 
 	logger        *log.Logger
 	pathsToRemove []string
+	details       src.Details
+
+	keepBackups bool
+	unpacked    []*rjson.UnpackedProduct
+
+	postDownloadHook src.PostDownloadHookFunc
 }
 
 func (*LatestVersion) IsSourceImpl() isrc.InstallSrcSigil {
@@ -55,6 +67,24 @@ func (lv *LatestVersion) SetLogger(logger *log.Logger) {
 	lv.logger = logger
 }
 
+func (lv *LatestVersion) SetPostDownloadHook(hook src.PostDownloadHookFunc) {
+	lv.postDownloadHook = hook
+}
+
+// SetKeepBackups makes Install keep backups of any files it replaces
+// until Commit is called. Remove restores them until then.
+func (lv *LatestVersion) SetKeepBackups(keep bool) {
+	lv.keepBackups = keep
+}
+
+// Commit discards backups of files replaced by Install
+func (lv *LatestVersion) Commit() {
+	for _, up := range lv.unpacked {
+		up.DiscardBackups()
+	}
+	lv.unpacked = nil
+}
+
 func (lv *LatestVersion) log() *log.Logger {
 	if lv.logger == nil {
 		return discardLogger
@@ -81,25 +111,24 @@ func (lv *LatestVersion) Install(ctx context.Context) (string, error) {
 	ctx, cancelFunc := context.WithTimeout(ctx, timeout)
 	defer cancelFunc()
 
-	// TODO: Introduce CheckWithContext to allow for cancellation
-	resp, err := checkpoint.Check(&checkpoint.CheckParams{
-		Product: lv.Product.Name,
-		OS:      runtime.GOOS,
-		Arch:    runtime.GOARCH,
-		Force:   true,
-	})
-	if err != nil {
-		return "", err
+	resolver := lv.Resolver
+	if resolver == nil {
+		resolver = &ReleaseIndexResolver{BaseURL: lv.ApiBaseURL}
+	}
+	if rs, ok := resolver.(loggerSettable); ok {
+		rs.SetLogger(lv.log())
 	}
 
-	latestVersion, err := version.NewVersion(resp.CurrentVersion)
+	latestVersion, err := resolver.ResolveLatest(ctx, lv.Product)
 	if err != nil {
 		return "", err
 	}
+	lv.log().Printf("resolved latest version of %s: %s", lv.Product.Name, latestVersion)
 
 	if lv.pathsToRemove == nil {
 		lv.pathsToRemove = make([]string, 0)
 	}
+	firstPathToRemove := len(lv.pathsToRemove)
 
 	dstDir := lv.InstallDir
 	if dstDir == "" {
@@ -115,6 +144,13 @@ func (lv *LatestVersion) Install(ctx context.Context) (string, error) {
 	lv.log().Printf("will install into dir at %s", dstDir)
 
 	rels := rjson.NewReleases()
+	if lv.Product.ReleasesBaseURL != "" {
+		rels.BaseURL = lv.Product.ReleasesBaseURL
+	}
+	rels.Backend = lv.Product.ReleasesBackend
+	if lv.ApiBaseURL != "" {
+		rels.BaseURL = lv.ApiBaseURL
+	}
 	rels.SetLogger(lv.log())
 	pv, err := rels.GetProductVersion(ctx, lv.Product.Name, latestVersion)
 	if err != nil {
@@ -125,16 +161,37 @@ func (lv *LatestVersion) Install(ctx context.Context) (string, error) {
 		Logger:           lv.log(),
 		VerifyChecksum:   !lv.SkipChecksumVerification,
 		ArmoredPublicKey: pubkey.DefaultPublicKey,
-		BaseURL:          rels.BaseURL,
+		BaseURL:          rels.DownloadBaseURL(),
+		KeepBackups:      lv.keepBackups,
+
+		ArchiveNameTemplate: lv.Product.ArchiveNameTemplate,
+		AcceptOctetStream:   rels.Backend == product.ReleasesBackendGitHub,
+	}
+	if len(lv.Product.ArchiveMembers) > 0 {
+		d.Rules = rjson.ExtractRulesForMembers(lv.Product.ArchiveMembers, lv.Product.BinaryName(), rjson.ExtractDirs{
+			BinDir:     dstDir,
+			LicenseDir: lv.LicenseDir,
+		})
+	}
+	if lv.Product.ArmoredPublicKey != "" {
+		d.ArmoredPublicKey = lv.Product.ArmoredPublicKey
 	}
 	if lv.ArmoredPublicKey != "" {
 		d.ArmoredPublicKey = lv.ArmoredPublicKey
 	}
 
+	if lv.postDownloadHook != nil {
+		d.PostDownload = func(ctx context.Context, archivePath string) error {
+			details := src.Details{Product: lv.Product.Name, Version: pv.Version}
+			return lv.postDownloadHook(ctx, details, archivePath)
+		}
+	}
+
 	licenseDir := lv.LicenseDir
 	up, err := d.DownloadAndUnpack(ctx, pv, dstDir, licenseDir)
 	if up != nil {
 		lv.pathsToRemove = append(lv.pathsToRemove, up.PathsToRemove...)
+		lv.unpacked = append(lv.unpacked, up)
 	}
 	if err != nil {
 		return "", err
@@ -150,9 +207,24 @@ func (lv *LatestVersion) Install(ctx context.Context) (string, error) {
 		return "", err
 	}
 
+	lv.details = src.Details{
+		Version:  latestVersion,
+		Files:    append([]string{}, lv.pathsToRemove[firstPathToRemove:]...),
+		Download: up.Download(),
+	}
+
 	return execPath, nil
 }
 
+// Details describes the version installed by the last Install call
+func (lv *LatestVersion) Details() src.Details {
+	details := lv.details
+	details.Product = lv.Product.Name
+	return details
+}
+
+// Remove removes all files created by Install and restores
+// any files it replaced, unless already committed (see Commit)
 func (lv *LatestVersion) Remove(ctx context.Context) error {
 	if lv.pathsToRemove != nil {
 		for _, path := range lv.pathsToRemove {
@@ -162,5 +234,11 @@ func (lv *LatestVersion) Remove(ctx context.Context) error {
 			}
 		}
 	}
+	lv.pathsToRemove = nil
+
+	for i := len(lv.unpacked) - 1; i >= 0; i-- {
+		lv.unpacked[i].RestoreBackups()
+	}
+	lv.unpacked = nil
 	return nil
 }
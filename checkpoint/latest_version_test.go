@@ -20,6 +20,9 @@ var (
 	_ src.Installable    = &LatestVersion{}
 	_ src.Removable      = &LatestVersion{}
 	_ src.LoggerSettable = &LatestVersion{}
+	_ src.Describable    = &LatestVersion{}
+
+	_ src.PostDownloadHookSettable = &LatestVersion{}
 )
 
 func TestLatestVersion(t *testing.T) {
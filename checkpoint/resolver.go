@@ -0,0 +1,250 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/chushi-io/lf-install/errors"
+	"github.com/chushi-io/lf-install/internal/httpclient"
+	rjson "github.com/chushi-io/lf-install/internal/releasesjson"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/hashicorp/go-version"
+)
+
+// LatestResolver resolves the latest stable version of a product
+type LatestResolver interface {
+	ResolveLatest(ctx context.Context, p product.Product) (*version.Version, error)
+}
+
+type loggerSettable interface {
+	SetLogger(logger *log.Logger)
+}
+
+var (
+	_ LatestResolver = &ReleaseIndexResolver{}
+	_ LatestResolver = &GitHubResolver{}
+	_ LatestResolver = &HTTPResolver{}
+)
+
+// ReleaseIndexResolver resolves the highest stable version
+// listed in the release index (index.json) of the product
+type ReleaseIndexResolver struct {
+	// BaseURL overrides URL of the releases API
+	// (Product.ReleasesBaseURL or the default one otherwise)
+	BaseURL string
+
+	logger *log.Logger
+}
+
+func (r *ReleaseIndexResolver) SetLogger(logger *log.Logger) {
+	r.logger = logger
+}
+
+func (r *ReleaseIndexResolver) ResolveLatest(ctx context.Context, p product.Product) (*version.Version, error) {
+	rels := rjson.NewReleases()
+	if p.ReleasesBaseURL != "" {
+		rels.BaseURL = p.ReleasesBaseURL
+	}
+	rels.Backend = p.ReleasesBackend
+	if r.BaseURL != "" {
+		rels.BaseURL = r.BaseURL
+	}
+	rels.SetLogger(loggerOrDiscard(r.logger))
+
+	pvs, err := rels.ListProductVersions(ctx, p.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *version.Version
+	for _, pv := range pvs {
+		v := pv.Version
+		// skip prereleases and enterprise versions
+		if v.Prerelease() != "" || v.Metadata() != "" {
+			continue
+		}
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+		}
+	}
+	if latest == nil {
+		return nil, &errors.VersionNotFoundError{
+			Product: p.Name,
+			Version: "latest",
+			Err:     fmt.Errorf("no stable version of %s found in %s", p.Name, rels.BaseURL),
+		}
+	}
+
+	return latest, nil
+}
+
+// GitHubResolver resolves the version of the latest GitHub release
+// of a repository (which excludes drafts and prereleases)
+type GitHubResolver struct {
+	// Repository represents the repository in owner/name form
+	// (e.g. opentofu/opentofu)
+	Repository string
+
+	// APIBaseURL overrides URL of the GitHub API
+	// (e.g. for GitHub Enterprise Server)
+	APIBaseURL string
+
+	// Token is used to authenticate (optional)
+	Token string
+
+	logger *log.Logger
+}
+
+const defaultGitHubAPIBaseURL = "https://api.github.com"
+
+func (r *GitHubResolver) SetLogger(logger *log.Logger) {
+	r.logger = logger
+}
+
+func (r *GitHubResolver) ResolveLatest(ctx context.Context, p product.Product) (*version.Version, error) {
+	owner, name, ok := strings.Cut(r.Repository, "/")
+	if !ok || owner == "" || name == "" {
+		return nil, fmt.Errorf("invalid repository %q, expected owner/name", r.Repository)
+	}
+
+	baseURL := defaultGitHubAPIBaseURL
+	if r.APIBaseURL != "" {
+		baseURL = strings.TrimSuffix(r.APIBaseURL, "/")
+	}
+	latestURL := fmt.Sprintf("%s/repos/%s/%s/releases/latest",
+		baseURL, url.PathEscape(owner), url.PathEscape(name))
+
+	headers := http.Header{}
+	headers.Set("Accept", "application/vnd.github+json")
+	if r.Token != "" {
+		headers.Set("Authorization", "Bearer "+r.Token)
+	}
+
+	var release struct {
+		TagName    string `json:"tag_name"`
+		Prerelease bool   `json:"prerelease"`
+	}
+	err := getJSON(ctx, loggerOrDiscard(r.logger), latestURL, headers, &release)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := version.NewVersion(strings.TrimPrefix(release.TagName, "v"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse version from tag %q: %w", release.TagName, err)
+	}
+	if release.Prerelease || v.Prerelease() != "" {
+		return nil, fmt.Errorf("latest release of %s (%s) is a prerelease", r.Repository, v)
+	}
+
+	return v, nil
+}
+
+// HTTPResolver resolves the latest version via a custom HTTP endpoint
+// responding with a JSON object containing the version
+type HTTPResolver struct {
+	// URL of the endpoint, in which {product} is replaced
+	// with the product name
+	URL string
+
+	// VersionField represents the top-level field containing the version
+	// (defaults to current_version, as used by Checkpoint)
+	VersionField string
+
+	// Header represents any additional headers to send
+	Header http.Header
+
+	logger *log.Logger
+}
+
+func (r *HTTPResolver) SetLogger(logger *log.Logger) {
+	r.logger = logger
+}
+
+func (r *HTTPResolver) ResolveLatest(ctx context.Context, p product.Product) (*version.Version, error) {
+	if r.URL == "" {
+		return nil, fmt.Errorf("no URL to resolve latest version from")
+	}
+	endpointURL := strings.ReplaceAll(r.URL, "{product}", url.PathEscape(p.Name))
+
+	field := r.VersionField
+	if field == "" {
+		field = "current_version"
+	}
+
+	var resp map[string]json.RawMessage
+	err := getJSON(ctx, loggerOrDiscard(r.logger), endpointURL, r.Header, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var rawVersion string
+	if err := json.Unmarshal(resp[field], &rawVersion); err != nil || rawVersion == "" {
+		return nil, fmt.Errorf("no version found in field %q of response from %s", field, endpointURL)
+	}
+
+	v, err := version.NewVersion(rawVersion)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse version %q: %w", rawVersion, err)
+	}
+
+	return v, nil
+}
+
+func getJSON(ctx context.Context, logger *log.Logger, u string, headers http.Header, v interface{}) error {
+	client := httpclient.NewHTTPClient(logger)
+
+	logger.Printf("requesting latest version from %s", u)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request for %q: %w", u, err)
+	}
+	for key, values := range headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return &errors.NetworkError{URL: u, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &errors.NetworkError{
+			URL:        u,
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("failed to obtain latest version from %q: %s", u, resp.Status),
+		}
+	}
+	logger.Printf("received %s", resp.Status)
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return &errors.NetworkError{URL: u, Err: err}
+	}
+
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		return fmt.Errorf("%w: failed to unmarshal response: %q", err, string(body))
+	}
+
+	return nil
+}
+
+func loggerOrDiscard(logger *log.Logger) *log.Logger {
+	if logger == nil {
+		return discardLogger
+	}
+	return logger
+}
@@ -0,0 +1,98 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package checkpoint
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chushi-io/lf-install/errors"
+	"github.com/chushi-io/lf-install/internal/testutil"
+	"github.com/chushi-io/lf-install/product"
+)
+
+func TestResolvers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/tofu/index.json":
+			w.Write([]byte(`{"name": "tofu", "versions": {
+				"1.7.3": {"version": "1.7.3"},
+				"1.8.0": {"version": "1.8.0"},
+				"1.9.0-beta1": {"version": "1.9.0-beta1"},
+				"1.8.1+ent": {"version": "1.8.1+ent"}
+			}}`))
+		case "/repos/opentofu/opentofu/releases/latest":
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"tag_name": "v1.8.2", "prerelease": false}`))
+		case "/latest/tofu":
+			w.Write([]byte(`{"current_version": "1.8.3"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	testCases := map[string]struct {
+		resolver        LatestResolver
+		expectedVersion string
+	}{
+		"release-index": {
+			resolver:        &ReleaseIndexResolver{BaseURL: srv.URL},
+			expectedVersion: "1.8.0",
+		},
+		"github": {
+			resolver: &GitHubResolver{
+				Repository: "opentofu/opentofu",
+				APIBaseURL: srv.URL,
+				Token:      "secret",
+			},
+			expectedVersion: "1.8.2",
+		},
+		"http": {
+			resolver:        &HTTPResolver{URL: srv.URL + "/latest/{product}"},
+			expectedVersion: "1.8.3",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tc.resolver.(loggerSettable).SetLogger(testutil.TestLogger())
+
+			v, err := tc.resolver.ResolveLatest(context.Background(), product.OpenTofu)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.String() != tc.expectedVersion {
+				t.Fatalf("expected version %s, got %s", tc.expectedVersion, v)
+			}
+		})
+	}
+}
+
+func TestResolvers_errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	_, err := (&HTTPResolver{URL: srv.URL}).ResolveLatest(context.Background(), product.OpenTofu)
+	var netErr *errors.NetworkError
+	if !stderrors.As(err, &netErr) || netErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected network error, got %#v", err)
+	}
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	cancelFunc()
+	_, err = (&GitHubResolver{Repository: "opentofu/opentofu", APIBaseURL: srv.URL}).ResolveLatest(ctx, product.OpenTofu)
+	if !stderrors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation error, got %#v", err)
+	}
+}
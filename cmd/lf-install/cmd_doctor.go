@@ -0,0 +1,170 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	stderrors "errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/cli"
+	"github.com/hashicorp/go-version"
+
+	"github.com/chushi-io/lf-install/errors"
+	"github.com/chushi-io/lf-install/fs"
+	"github.com/chushi-io/lf-install/product"
+)
+
+type DoctorCommand struct {
+	Ui cli.Ui
+}
+
+func (c *DoctorCommand) Name() string { return "doctor" }
+
+func (c *DoctorCommand) Synopsis() string {
+	return "Check whether installed versions are still supported"
+}
+
+func (c *DoctorCommand) Help() string {
+	helpText := `
+Usage: lf-install doctor [options] [<product>...]
+
+  This command checks whether versions of products found in PATH
+  (all known products by default) are past end of support, and exits
+  with a non-zero status if any of them is. Versions without lifecycle
+  data are reported with unknown support status.
+  Options:
+    -version  Check the given version instead of the one found in PATH
+              (requires a single product).
+    -lifecycle-file
+              Path to JSON file with lifecycle of the product, overriding
+              the bundled one (requires a single product).
+    -products-file
+              Path to JSON file with definitions of additional products.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DoctorCommand) Run(args []string) int {
+	var (
+		rawVersion    string
+		lifecycleFile string
+		productsFile  string
+	)
+
+	flags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&rawVersion, "version", "", "version to check")
+	flags.StringVar(&lifecycleFile, "lifecycle-file", "", "path to JSON file with lifecycle of the product")
+	flags.StringVar(&productsFile, "products-file", "", "path to JSON file with product definitions")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if productsFile != "" {
+		if err := registerProductsFile(productsFile); err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+	}
+
+	names := flags.Args()
+	if len(names) == 0 {
+		names = product.Names()
+	}
+	if (rawVersion != "" || lifecycleFile != "") && len(names) != 1 {
+		c.Ui.Error("-version and -lifecycle-file flags require a single product")
+		return 1
+	}
+
+	var requestedVersion *version.Version
+	if rawVersion != "" {
+		v, err := version.NewVersion(rawVersion)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("invalid version: %s", err))
+			return 1
+		}
+		requestedVersion = v
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	exitCode := 0
+	for _, name := range names {
+		p, ok := product.Lookup(name)
+		if !ok {
+			c.Ui.Error(fmt.Sprintf("unknown product %q (known products: %s)",
+				name, strings.Join(product.Names(), ", ")))
+			return 1
+		}
+
+		if lifecycleFile != "" {
+			lc, err := product.LoadLifecycleFile(lifecycleFile)
+			if err != nil {
+				c.Ui.Error(fmt.Sprintf("unable to load lifecycle: %s", err))
+				return 1
+			}
+			p.Lifecycle = lc
+		}
+
+		if !c.check(ctx, name, p, requestedVersion) {
+			exitCode = 1
+		}
+	}
+
+	return exitCode
+}
+
+// check reports support status of the version of the product, or the one
+// found in PATH if v is nil, and returns false if it's past end of support.
+// Versions without lifecycle data are reported as unknown, not supported.
+func (c *DoctorCommand) check(ctx context.Context, name string, p product.Product, v *version.Version) bool {
+	label := name
+	if v == nil {
+		execPath, err := (&fs.AnyVersion{Product: &p}).Find(ctx)
+		if err != nil {
+			c.Ui.Output(fmt.Sprintf("%s: not found in PATH", name))
+			return true
+		}
+		// prefer build info over executing whatever is found in PATH
+		v, err = fs.VersionGetter(p, fs.DetectByBuildInfo)(ctx, execPath)
+		if err != nil {
+			c.Ui.Warn(fmt.Sprintf("%s (%s): unable to determine version: %s", name, execPath, err))
+			return true
+		}
+		label = fmt.Sprintf("%s %s (%s)", name, v, execPath)
+	} else {
+		label = fmt.Sprintf("%s %s", name, v)
+	}
+
+	if p.Lifecycle == nil {
+		c.Ui.Warn(fmt.Sprintf("%s: support status unknown (no lifecycle data)", label))
+		return true
+	}
+
+	err := p.Lifecycle.CheckSupport(p.Name, v, time.Now())
+	var eosErr *errors.EndOfSupportError
+	if stderrors.As(err, &eosErr) {
+		c.Ui.Error(fmt.Sprintf("%s: past end of support since %s (cycle %s)",
+			label, eosErr.EndOfSupport.Format(time.DateOnly), eosErr.Cycle))
+		return false
+	}
+
+	cycle, ok := p.Lifecycle.Cycle(v)
+	if !ok {
+		// absence of lifecycle data doesn't show the version is supported
+		c.Ui.Warn(fmt.Sprintf("%s: support status unknown (no lifecycle data for this version)", label))
+		return true
+	}
+	c.Ui.Output(fmt.Sprintf("%s: supported (cycle %s)", label, cycle.Cycle))
+	return true
+}
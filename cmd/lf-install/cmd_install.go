@@ -10,15 +10,22 @@ import (
 	"io"
 	"log"
 	"os"
-	"runtime"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/hashicorp/cli"
 	"github.com/hashicorp/go-version"
 
 	hci "github.com/chushi-io/lf-install"
+	"github.com/chushi-io/lf-install/advisory"
+	"github.com/chushi-io/lf-install/fs"
 	"github.com/chushi-io/lf-install/product"
+	"github.com/chushi-io/lf-install/provenance"
 	"github.com/chushi-io/lf-install/releases"
+	"github.com/chushi-io/lf-install/sbom"
 	"github.com/chushi-io/lf-install/src"
 )
 
@@ -35,14 +42,65 @@ func (c *InstallCommand) Synopsis() string {
 func (c *InstallCommand) Help() string {
 	helpText := `
 Usage: lf-install install [options] -version <version> <product>
+       lf-install install [options] -select <strategy> [-version <constraints>] <product>
 
   This command installs a linux Foundation product.
+  Known products: tofu (opentofu), bao (openbao)
   Options:
     -version  [REQUIRED] Version of product to install.
+              With -select, optional version constraints instead.
+    -select   Strategy to select a version from those released:
+              highest, lowest, latest-patch, previous-minor,
+              or newer-prerelease.
+    -current-version
+              Version whose latest patch is selected by latest-patch.
+              Defaults to the version of the product found in PATH.
+    -min-age  Minimum age of a release to be selected, e.g. 7d or 36h
+              (requires -select).
+    -as-of    Select a version as it would have been selected at the
+              given time, e.g. 2024-01-31T12:00:00Z, or by the end
+              of the given day (in UTC), e.g. 2024-01-31
+              (requires -select).
     -path     Path to directory where the product will be installed.
               Defaults to current working directory.
     -log-file Path to file where logs will be written. /dev/stdout
               or /dev/stderr can be used to log to STDOUT/STDERR.
+    -products-file
+              Path to JSON file with definitions of additional products.
+    -yanked-file
+              Path to JSON file listing yanked versions, in addition to
+              those marked in the index or yanked.json of the mirror.
+              Yanked versions are never selected.
+    -refuse-yanked
+              Fail instead of warning when -version is yanked.
+    -advisories
+              Directory or URL of a ZIP archive (e.g. all.zip from an
+              OSV mirror) with OSV advisories to check the version against.
+    -advisory-action
+              What to do when the version has known vulnerabilities:
+              warn (default), fail, or skip (affected versions are not
+              selected by -select, fails otherwise).
+    -provenance-key
+              Path to PEM file with public keys trusted to sign SLSA
+              provenance attestations. If set, provenance of the archive
+              is verified before it is unpacked (requires -provenance-file).
+    -provenance-file
+              Path to file with attestations (e.g. multiple.intoto.jsonl)
+              signed by one of the keys.
+    -provenance-builder
+              Comma-separated IDs of trusted builders. IDs without @
+              match the builder at any ref.
+    -provenance-repo
+              Repository the archive must be built from,
+              e.g. github.com/opentofu/opentofu.
+    -provenance-tag
+              Tag the archive must be built from, with {version} replaced
+              by the version. Defaults to v{version}.
+    -sbom     Path to file where a software bill of materials describing
+              the installed product will be written.
+    -sbom-format
+              Format of the software bill of materials:
+              spdx (SPDX 2.3 JSON, default) or cyclonedx (CycloneDX 1.5 JSON).
 `
 	return strings.TrimSpace(helpText)
 }
@@ -52,6 +110,23 @@ func (c *InstallCommand) Run(args []string) int {
 		version        string
 		installDirPath string
 		logFilePath    string
+		productsFile   string
+		selection      string
+		currentVersion string
+		minAge         string
+		asOf           string
+		yankedFile     string
+		refuseYanked   bool
+		advisories     string
+		advisoryAction string
+		sbomPath       string
+		sbomFormat     string
+
+		provenanceKey     string
+		provenanceFile    string
+		provenanceBuilder string
+		provenanceRepo    string
+		provenanceTag     string
 	)
 
 	fs := flag.NewFlagSet("install", flag.ExitOnError)
@@ -59,6 +134,22 @@ func (c *InstallCommand) Run(args []string) int {
 	fs.StringVar(&version, "version", "", "version of product to install")
 	fs.StringVar(&installDirPath, "path", "", "path to directory where production will be installed")
 	fs.StringVar(&logFilePath, "log-file", "", "path to file where logs will be written")
+	fs.StringVar(&productsFile, "products-file", "", "path to JSON file with product definitions")
+	fs.StringVar(&selection, "select", "", "strategy to select a version")
+	fs.StringVar(&currentVersion, "current-version", "", "version whose latest patch to select")
+	fs.StringVar(&minAge, "min-age", "", "minimum age of a release to be selected")
+	fs.StringVar(&asOf, "as-of", "", "time to select a version as of")
+	fs.StringVar(&yankedFile, "yanked-file", "", "path to JSON file listing yanked versions")
+	fs.BoolVar(&refuseYanked, "refuse-yanked", false, "fail when the version is yanked")
+	fs.StringVar(&advisories, "advisories", "", "directory or URL with OSV advisories")
+	fs.StringVar(&advisoryAction, "advisory-action", "warn", "action for versions with known vulnerabilities")
+	fs.StringVar(&provenanceKey, "provenance-key", "", "path to PEM file with keys trusted to sign provenance")
+	fs.StringVar(&provenanceFile, "provenance-file", "", "path to file with provenance attestations")
+	fs.StringVar(&provenanceBuilder, "provenance-builder", "", "comma-separated IDs of trusted builders")
+	fs.StringVar(&provenanceRepo, "provenance-repo", "", "repository the archive must be built from")
+	fs.StringVar(&provenanceTag, "provenance-tag", "v{version}", "tag the archive must be built from")
+	fs.StringVar(&sbomPath, "sbom", "", "path to file where SBOM will be written")
+	fs.StringVar(&sbomFormat, "sbom-format", "spdx", "format of the SBOM")
 
 	if err := fs.Parse(args); err != nil {
 		return 1
@@ -73,12 +164,75 @@ func (c *InstallCommand) Run(args []string) int {
 Option flags must be provided before the positional argument`)
 		return 1
 	}
-	product := fs.Args()[0]
+	productName := fs.Args()[0]
 
-	if version == "" {
+	if version == "" && selection == "" {
 		c.Ui.Error("-version flag is required")
 		return 1
 	}
+	if currentVersion != "" && selection == "" {
+		c.Ui.Error("-current-version flag requires -select")
+		return 1
+	}
+	if (minAge != "" || asOf != "") && selection == "" {
+		c.Ui.Error("-min-age and -as-of flags require -select")
+		return 1
+	}
+
+	opts := installOptions{
+		yankPolicy: releases.YankPolicy{
+			CheckMirror: true,
+			File:        yankedFile,
+			RefuseExact: refuseYanked,
+		},
+	}
+	if minAge != "" {
+		d, err := parseAge(minAge)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("invalid -min-age: %s", err))
+			return 1
+		}
+		opts.minimumAge = d
+	}
+	if asOf != "" {
+		t, err := parseAsOf(asOf)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("invalid -as-of: %s", err))
+			return 1
+		}
+		opts.asOf = t
+	}
+
+	if provenanceKey != "" {
+		if provenanceFile == "" {
+			c.Ui.Error("-provenance-key requires -provenance-file")
+			return 1
+		}
+		keys, err := provenance.LoadPublicKeysFile(provenanceKey)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("invalid -provenance-key: %s", err))
+			return 1
+		}
+		opts.provenance = &provenance.Policy{
+			PublicKeys: keys,
+			Repository: provenanceRepo,
+			Tag:        provenanceTag,
+			File:       provenanceFile,
+		}
+		if provenanceBuilder != "" {
+			opts.provenance.BuilderIDs = strings.Split(provenanceBuilder, ",")
+		}
+	} else if provenanceFile != "" || provenanceBuilder != "" || provenanceRepo != "" {
+		c.Ui.Error("-provenance-* flags require -provenance-key")
+		return 1
+	}
+
+	if productsFile != "" {
+		if err := registerProductsFile(productsFile); err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+	}
 
 	if installDirPath == "" {
 		cwd, err := os.Getwd()
@@ -99,42 +253,283 @@ Option flags must be provided before the positional argument`)
 		logger = log.New(f, "[DEBUG] ", log.LstdFlags|log.Lshortfile|log.Lmicroseconds)
 	}
 
-	installedPath, err := c.install(product, version, installDirPath, logger)
+	// Interrupting the installation cancels any in-flight download
+	// and unpacking, after which we clean up whatever was left behind.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if advisories != "" {
+		policy, err := loadAdvisoryPolicy(ctx, logger, advisories, advisoryAction)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		opts.advisories = policy
+	}
+
+	i := hci.NewInstaller()
+	i.SetLogger(logger)
+
+	if sbomPath != "" {
+		format, err := sbom.ParseFormat(sbomFormat)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		i.AddHook(&hci.SBOMWriter{Format: format, Path: sbomPath})
+	}
+
+	var (
+		installedPath string
+		err           error
+	)
+	label := version
+	if selection != "" {
+		installedPath, label, err = c.installSelected(ctx, i, productName, selection, version, currentVersion, opts, installDirPath)
+	} else {
+		installedPath, err = c.install(ctx, i, productName, version, opts, installDirPath)
+	}
 	if err != nil {
-		msg := fmt.Sprintf("failed to install %s@%s: %v", product, version, err)
+		// removes anything installed and restores any binary
+		// it replaced, unless the installation succeeded as a whole
+		if rmErr := i.Remove(context.Background()); rmErr != nil {
+			logger.Printf("failed to clean up after failed installation: %s", rmErr)
+		}
+		msg := fmt.Sprintf("failed to install %s@%s: %v", productName, label, err)
 		c.Ui.Error(msg)
 		return 1
 	}
 
-	c.Ui.Info(fmt.Sprintf("installed %s@%s to %s", product, version, installedPath))
+	c.Ui.Info(fmt.Sprintf("installed %s@%s to %s", productName, label, installedPath))
 	return 0
 }
 
-func (c *InstallCommand) install(project, tag, installDirPath string, logger *log.Logger) (string, error) {
+func (c *InstallCommand) install(ctx context.Context, i *hci.Installer, project, tag string, opts installOptions, installDirPath string) (string, error) {
 	msg := fmt.Sprintf("lf-install: will install %s@%s", project, tag)
 	c.Ui.Info(msg)
 
+	p, ok := product.Lookup(project)
+	if !ok {
+		return "", fmt.Errorf("unknown product %q (known products: %s)",
+			project, strings.Join(product.Names(), ", "))
+	}
+
 	v, err := version.NewVersion(tag)
 	if err != nil {
 		return "", fmt.Errorf("invalid version: %w", err)
 	}
-	i := hci.NewInstaller()
-	i.SetLogger(logger)
-
 	source := &releases.ExactVersion{
-		Product: product.Product{
-			Name: project,
-			BinaryName: func() string {
-				if runtime.GOOS == "windows" {
-					return fmt.Sprintf("%s.exe", project)
-				}
-				return project
-			},
-		},
+		Product:    p,
 		Version:    v,
 		InstallDir: installDirPath,
+		YankPolicy: opts.yankPolicy,
+		Advisories: opts.advisories,
+		Provenance: opts.provenance,
+	}
+
+	installedPath, err := i.Install(ctx, []src.Installable{source})
+	if err != nil {
+		return "", err
+	}
+	c.warnYanked(project, source)
+	c.warnAdvisories(project, source.Details())
+	c.warnEndOfSupport(p, source.Details())
+	return installedPath, nil
+}
+
+// installSelected installs the version picked by the selection strategy
+// out of those matching constraints, returning the path and the version
+// (or the strategy, if the version couldn't be determined)
+func (c *InstallCommand) installSelected(ctx context.Context, i *hci.Installer, project, selection, constraints, current string,
+	opts installOptions, installDirPath string) (string, string, error) {
+	label := selection
+	if constraints != "" {
+		label = fmt.Sprintf("%s(%s)", selection, constraints)
+	}
+	c.Ui.Info(fmt.Sprintf("lf-install: will install %s@%s", project, label))
+
+	p, ok := product.Lookup(project)
+	if !ok {
+		return "", label, fmt.Errorf("unknown product %q (known products: %s)",
+			project, strings.Join(product.Names(), ", "))
+	}
+
+	s, err := releases.ParseSelection(selection)
+	if err != nil {
+		return "", label, err
+	}
+
+	source := &releases.LatestVersion{
+		Product:    p,
+		Selection:  s,
+		MinimumAge: opts.minimumAge,
+		AsOf:       opts.asOf,
+		YankPolicy: opts.yankPolicy,
+		Advisories: opts.advisories,
+		Provenance: opts.provenance,
+		InstallDir: installDirPath,
+	}
+	if constraints != "" {
+		source.Constraints, err = version.NewConstraint(constraints)
+		if err != nil {
+			return "", label, fmt.Errorf("invalid version constraints: %w", err)
+		}
+	}
+
+	if s == releases.SelectLatestPatch {
+		source.CurrentVersion, err = resolveCurrentVersion(ctx, p, current)
+		if err != nil {
+			return "", label, err
+		}
+	}
+
+	installedPath, err := i.Install(ctx, []src.Installable{source})
+	if err != nil {
+		return "", label, err
+	}
+	details := source.Details()
+	if details.Version != nil {
+		label = details.Version.String()
+	}
+	c.warnAdvisories(project, details)
+	c.warnEndOfSupport(p, details)
+	return installedPath, label, nil
+}
+
+// warnYanked reports the installed version
+// if it was yanked (withdrawn) upstream
+func (c *InstallCommand) warnYanked(project string, source *releases.ExactVersion) {
+	reason, ok := source.Yanked()
+	if !ok {
+		return
+	}
+	msg := fmt.Sprintf("%s@%s was yanked upstream", project, source.Version)
+	if reason != "" {
+		msg += ": " + reason
+	}
+	c.Ui.Warn(msg + " (use -refuse-yanked to fail instead)")
+}
+
+// warnEndOfSupport reports the installed version
+// if it's past end of support
+func (c *InstallCommand) warnEndOfSupport(p product.Product, details src.Details) {
+	if details.Version == nil {
+		return
+	}
+	if err := p.Lifecycle.CheckSupport(p.Name, details.Version, time.Now()); err != nil {
+		c.Ui.Warn(err.Error())
+	}
+}
+
+// warnAdvisories reports known vulnerabilities
+// affecting the installed version
+func (c *InstallCommand) warnAdvisories(project string, details src.Details) {
+	for _, a := range details.Advisories {
+		id := a.ID
+		if len(a.Aliases) > 0 {
+			id += " (" + strings.Join(a.Aliases, ", ") + ")"
+		}
+		msg := fmt.Sprintf("%s@%s is affected by %s: %s", project, details.Version, id, a.Summary)
+		if len(a.FixedVersions) > 0 {
+			msg += fmt.Sprintf(" (fixed in %s)", strings.Join(a.FixedVersions, ", "))
+		}
+		c.Ui.Warn(msg)
+	}
+}
+
+// resolveCurrentVersion parses the given version, or obtains
+// the version of the product found in PATH if it's empty
+func resolveCurrentVersion(ctx context.Context, p product.Product, raw string) (*version.Version, error) {
+	if raw != "" {
+		v, err := version.NewVersion(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid current version: %w", err)
+		}
+		return v, nil
+	}
+
+	if p.GetVersion == nil {
+		return nil, fmt.Errorf("-current-version flag is required for %s", p.Name)
+	}
+	execPath, err := (&fs.AnyVersion{Product: &p}).Find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find current version (use -current-version): %w", err)
+	}
+	return p.GetVersion(ctx, execPath)
+}
+
+// installOptions restrict which versions are selected or installed
+type installOptions struct {
+	minimumAge time.Duration
+	asOf       time.Time
+	yankPolicy releases.YankPolicy
+	advisories *advisory.Policy
+	provenance *provenance.Policy
+}
+
+// loadAdvisoryPolicy loads OSV advisories from a directory,
+// or downloads them from a URL of a ZIP archive
+func loadAdvisoryPolicy(ctx context.Context, logger *log.Logger, location, action string) (*advisory.Policy, error) {
+	a, err := advisory.ParseAction(action)
+	if err != nil {
+		return nil, err
+	}
+
+	var db *advisory.Database
+	if strings.HasPrefix(location, "https://") || strings.HasPrefix(location, "http://") {
+		db, err = advisory.Fetch(ctx, logger, location)
+	} else {
+		db, err = advisory.LoadDir(location)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to load advisories from %q: %w", location, err)
+	}
+
+	return &advisory.Policy{Database: db, Action: a}, nil
+}
+
+// registerProductsFile registers products defined in the JSON file at path
+func registerProductsFile(path string) error {
+	defs, err := product.LoadDefinitionsFile(path)
+	if err == nil {
+		err = product.RegisterDefinitions(defs)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to load products from %q: %w", path, err)
+	}
+	return nil
+}
+
+// parseAge parses a duration, additionally accepting
+// a whole number of days (e.g. 7d)
+func parseAge(raw string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("expected number of days, got %q", raw)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("age must not be negative")
 	}
+	return d, nil
+}
 
-	ctx := context.Background()
-	return i.Install(ctx, []src.Installable{source})
+// parseAsOf parses either RFC 3339 time or a date (in UTC),
+// which represents the end of the day, i.e. includes releases
+// made at any time that day
+func parseAsOf(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected date (YYYY-MM-DD) or RFC 3339 time, got %q", raw)
+	}
+	return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
 }
@@ -0,0 +1,129 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/hashicorp/cli"
+	"github.com/hashicorp/go-version"
+
+	"github.com/chushi-io/lf-install/product"
+	"github.com/chushi-io/lf-install/releases"
+)
+
+type ListCommand struct {
+	Ui cli.Ui
+}
+
+func (c *ListCommand) Name() string { return "list" }
+
+func (c *ListCommand) Synopsis() string {
+	return "List released versions of a Linux Foundation product"
+}
+
+func (c *ListCommand) Help() string {
+	helpText := `
+Usage: lf-install list [options] <product>
+
+  This command lists released versions of a Linux Foundation product.
+  Yanked versions are listed with the reason why they were yanked.
+  Options:
+    -version  Version constraints to filter versions by.
+    -products-file
+              Path to JSON file with definitions of additional products.
+    -yanked-file
+              Path to JSON file listing yanked versions, in addition to
+              those marked in the index or yanked.json of the mirror.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ListCommand) Run(args []string) int {
+	var (
+		constraints  string
+		productsFile string
+		yankedFile   string
+	)
+
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	fs.Usage = func() { c.Ui.Output(c.Help()) }
+	fs.StringVar(&constraints, "version", "", "version constraints")
+	fs.StringVar(&productsFile, "products-file", "", "path to JSON file with product definitions")
+	fs.StringVar(&yankedFile, "yanked-file", "", "path to JSON file listing yanked versions")
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	args = fs.Args()
+	if len(args) != 1 {
+		c.Ui.Error(`This command requires one positional argument: <product>
+Option flags must be provided before the positional argument`)
+		return 1
+	}
+	productName := args[0]
+
+	if productsFile != "" {
+		if err := registerProductsFile(productsFile); err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+	}
+
+	p, ok := product.Lookup(productName)
+	if !ok {
+		c.Ui.Error(fmt.Sprintf("unknown product %q (known products: %s)",
+			productName, strings.Join(product.Names(), ", ")))
+		return 1
+	}
+
+	v := &releases.Versions{
+		Product: p,
+		YankPolicy: releases.YankPolicy{
+			CheckMirror: true,
+			File:        yankedFile,
+		},
+	}
+	if constraints != "" {
+		vc, err := version.NewConstraint(constraints)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("invalid version constraints: %s", err))
+			return 1
+		}
+		v.Constraints = vc
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	sources, err := v.List(ctx)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("failed to list %s versions: %s", productName, err))
+		return 1
+	}
+
+	for _, s := range sources {
+		ev, ok := s.(*releases.ExactVersion)
+		if !ok {
+			continue
+		}
+		line := ev.Version.String()
+		if reason, yanked := ev.Yanked(); yanked {
+			line += " (yanked"
+			if reason != "" {
+				line += ": " + reason
+			}
+			line += ")"
+		}
+		c.Ui.Output(line)
+	}
+	return 0
+}
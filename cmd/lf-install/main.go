@@ -39,6 +39,16 @@ func main() {
 				Ui: ui,
 			}, nil
 		},
+		"doctor": func() (cli.Command, error) {
+			return &DoctorCommand{
+				Ui: ui,
+			}, nil
+		},
+		"list": func() (cli.Command, error) {
+			return &ListCommand{
+				Ui: ui,
+			}, nil
+		},
 	}
 
 	exitStatus, err := c.Run()
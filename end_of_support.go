@@ -0,0 +1,39 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package install
+
+import (
+	"context"
+	"io"
+	"log"
+
+	"github.com/chushi-io/lf-install/product"
+)
+
+var _ PostInstallHook = &EndOfSupportCheck{}
+
+// EndOfSupportCheck is a hook which checks that the version of Product
+// found, installed or built is not past end of support per Product.Lifecycle.
+//
+// With product.EndOfSupportFail the installation fails (and anything
+// installed is removed), otherwise a warning is logged.
+type EndOfSupportCheck struct {
+	Product product.Product
+	Action  product.EndOfSupportAction
+
+	// Logger receives warnings (discarded if nil)
+	Logger *log.Logger
+}
+
+func (c *EndOfSupportCheck) PostInstall(ctx context.Context, event HookEvent) error {
+	if event.Product != c.Product.Name {
+		return nil
+	}
+
+	logger := c.Logger
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+	return product.CheckEndOfSupport(logger, c.Product, event.Version, c.Action)
+}
@@ -3,6 +3,66 @@
 
 package errors
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrVersionNotFound indicates that the requested version
+	// (or any version matching constraints) does not exist
+	ErrVersionNotFound = errors.New("version not found")
+
+	// ErrNoBuildForPlatform indicates that the version exists
+	// but there is no build for the current OS and architecture
+	ErrNoBuildForPlatform = errors.New("no build for platform")
+
+	// ErrSignatureInvalid indicates that the signature
+	// of downloaded checksums could not be verified
+	ErrSignatureInvalid = errors.New("signature invalid")
+
+	// ErrChecksumMismatch indicates that the checksum of a downloaded
+	// archive does not match the verified checksum
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrSizeMismatch indicates that a download was truncated
+	// or otherwise differs in size from what the server announced
+	ErrSizeMismatch = errors.New("size mismatch")
+
+	// ErrTransient indicates a network or server failure
+	// which may succeed if retried later
+	ErrTransient = errors.New("transient network error")
+
+	// ErrVersionMismatch indicates that an installed binary
+	// reports a different version than the one requested
+	ErrVersionMismatch = errors.New("version mismatch")
+
+	// ErrBuildFailed indicates that building a product from source failed
+	ErrBuildFailed = errors.New("build failed")
+
+	// ErrVersionYanked indicates that the requested version
+	// was yanked (withdrawn) upstream
+	ErrVersionYanked = errors.New("version yanked")
+
+	// ErrVulnerableVersion indicates that the requested version
+	// is affected by known vulnerabilities
+	ErrVulnerableVersion = errors.New("vulnerable version")
+
+	// ErrEndOfSupport indicates that the version
+	// is past end of support
+	ErrEndOfSupport = errors.New("end of support")
+
+	// ErrProvenanceInvalid indicates that no attestation satisfying
+	// the provenance policy was found for a downloaded archive
+	ErrProvenanceInvalid = errors.New("provenance invalid")
+
+	// ErrReleaseTimeUnavailable indicates that versions were to be
+	// selected by release time, which the releases API doesn't record
+	ErrReleaseTimeUnavailable = errors.New("release timestamps unavailable")
+)
+
 type skippableErr struct {
 	Err error
 }
@@ -11,11 +71,253 @@ func (e skippableErr) Error() string {
 	return e.Err.Error()
 }
 
+func (e skippableErr) Unwrap() error {
+	return e.Err
+}
+
 func SkippableErr(err error) skippableErr {
 	return skippableErr{Err: err}
 }
 
 func IsErrorSkippable(err error) bool {
-	_, ok := err.(skippableErr)
-	return ok
+	var se skippableErr
+	return errors.As(err, &se)
+}
+
+// VersionNotFoundError is returned when the requested version
+// of a product cannot be found
+type VersionNotFoundError struct {
+	Product string
+	// Version represents the requested version or constraints
+	Version string
+	Err     error
+}
+
+func (e *VersionNotFoundError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s %s not found", e.Product, e.Version)
+}
+
+func (e *VersionNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+func (e *VersionNotFoundError) Is(target error) bool {
+	return target == ErrVersionNotFound
+}
+
+// NoBuildError is returned when a version has no build
+// for the given OS and architecture
+type NoBuildError struct {
+	Product string
+	Version string
+	OS      string
+	Arch    string
+}
+
+func (e *NoBuildError) Error() string {
+	return fmt.Sprintf("no ZIP archive found for %s %s %s/%s",
+		e.Product, e.Version, e.OS, e.Arch)
+}
+
+func (e *NoBuildError) Is(target error) bool {
+	return target == ErrNoBuildForPlatform
+}
+
+// SignatureError is returned when a signature cannot be verified
+type SignatureError struct {
+	Err error
+}
+
+func (e *SignatureError) Error() string {
+	return fmt.Sprintf("unable to verify checksums signature: %s", e.Err)
+}
+
+func (e *SignatureError) Unwrap() error {
+	return e.Err
+}
+
+func (e *SignatureError) Is(target error) bool {
+	return target == ErrSignatureInvalid
+}
+
+// ChecksumMismatchError is returned when a downloaded file
+// does not match its verified checksum
+type ChecksumMismatchError struct {
+	Filename string
+	Expected []byte
+	Actual   []byte
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch (expected: %x, got: %x)",
+		e.Expected, e.Actual)
+}
+
+func (e *ChecksumMismatchError) Is(target error) bool {
+	return target == ErrChecksumMismatch
+}
+
+// SizeMismatchError is returned when the size of a downloaded
+// file does not match the expected size
+type SizeMismatchError struct {
+	Filename string
+	Expected int64
+	Actual   int64
+}
+
+func (e *SizeMismatchError) Error() string {
+	return fmt.Sprintf("unexpected size (downloaded: %d, expected: %d)",
+		e.Actual, e.Expected)
+}
+
+func (e *SizeMismatchError) Is(target error) bool {
+	return target == ErrSizeMismatch
+}
+
+// VersionMismatchError is returned when an installed binary
+// reports a different version than the one which was resolved
+type VersionMismatchError struct {
+	Product  string
+	ExecPath string
+	Expected string
+	Actual   string
+}
+
+func (e *VersionMismatchError) Error() string {
+	return fmt.Sprintf("%s reports version %s, expected %s",
+		e.ExecPath, e.Actual, e.Expected)
+}
+
+func (e *VersionMismatchError) Is(target error) bool {
+	return target == ErrVersionMismatch
+}
+
+// VersionYankedError is returned when the requested version
+// was yanked (withdrawn) upstream and the policy refuses to install it
+type VersionYankedError struct {
+	Product string
+	Version string
+
+	// Reason represents why the version was yanked (may be empty)
+	Reason string
+}
+
+func (e *VersionYankedError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%s %s was yanked: %s", e.Product, e.Version, e.Reason)
+	}
+	return fmt.Sprintf("%s %s was yanked", e.Product, e.Version)
+}
+
+func (e *VersionYankedError) Is(target error) bool {
+	return target == ErrVersionYanked
+}
+
+// VulnerableVersionError is returned when the requested version
+// is affected by known vulnerabilities and the policy refuses to install it
+type VulnerableVersionError struct {
+	Product string
+	Version string
+
+	// AdvisoryIDs represents identifiers of advisories affecting the version
+	AdvisoryIDs []string
+}
+
+func (e *VulnerableVersionError) Error() string {
+	return fmt.Sprintf("%s %s is affected by known vulnerabilities: %s",
+		e.Product, e.Version, strings.Join(e.AdvisoryIDs, ", "))
+}
+
+func (e *VulnerableVersionError) Is(target error) bool {
+	return target == ErrVulnerableVersion
+}
+
+// EndOfSupportError is returned when a version
+// is past end of support and the policy rejects it
+type EndOfSupportError struct {
+	Product string
+	Version string
+
+	// Cycle represents the minor version (e.g. 1.6)
+	Cycle string
+
+	EndOfSupport time.Time
+}
+
+func (e *EndOfSupportError) Error() string {
+	return fmt.Sprintf("%s %s is past end of support (%s reached end of support on %s)",
+		e.Product, e.Version, e.Cycle, e.EndOfSupport.Format("2006-01-02"))
+}
+
+func (e *EndOfSupportError) Is(target error) bool {
+	return target == ErrEndOfSupport
+}
+
+// ProvenanceError is returned when provenance
+// of a downloaded archive cannot be verified
+type ProvenanceError struct {
+	Filename string
+	Err      error
+}
+
+func (e *ProvenanceError) Error() string {
+	return fmt.Sprintf("unable to verify provenance of %s: %s", e.Filename, e.Err)
+}
+
+func (e *ProvenanceError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ProvenanceError) Is(target error) bool {
+	return target == ErrProvenanceInvalid
+}
+
+// NetworkError is returned when a request fails or the server
+// responds with an unexpected status code
+type NetworkError struct {
+	URL string
+
+	// StatusCode of the response (0 if none was received)
+	StatusCode int
+
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports the error as ErrTransient when no response was received,
+// or the server responded with a status code indicating temporary failure
+func (e *NetworkError) Is(target error) bool {
+	return target == ErrTransient && e.IsTransient()
+}
+
+func (e *NetworkError) IsTransient() bool {
+	return e.StatusCode == 0 || e.StatusCode == 429 || e.StatusCode >= 500
+}
+
+// BuildError is returned when building a product from source fails
+type BuildError struct {
+	Product string
+	Err     error
+}
+
+func (e *BuildError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BuildError) Unwrap() error {
+	return e.Err
+}
+
+func (e *BuildError) Is(target error) bool {
+	return target == ErrBuildFailed
 }
@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+func TestErrors_Is(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		target   error
+		expected bool
+	}{
+		"checksum-mismatch": {
+			err:      &ChecksumMismatchError{Expected: []byte{1}, Actual: []byte{2}},
+			target:   ErrChecksumMismatch,
+			expected: true,
+		},
+		"network-no-response": {
+			err:      &NetworkError{Err: fmt.Errorf("connection refused")},
+			target:   ErrTransient,
+			expected: true,
+		},
+		"network-server-error": {
+			err:      &NetworkError{StatusCode: 503, Err: fmt.Errorf("unavailable")},
+			target:   ErrTransient,
+			expected: true,
+		},
+		"network-not-found": {
+			err:      &NetworkError{StatusCode: 404, Err: fmt.Errorf("not found")},
+			target:   ErrTransient,
+			expected: false,
+		},
+		"version-not-found-wrapping-network": {
+			err: &VersionNotFoundError{
+				Err: &NetworkError{StatusCode: 404, Err: fmt.Errorf("not found")},
+			},
+			target:   ErrVersionNotFound,
+			expected: true,
+		},
+		"version-yanked": {
+			err:      fmt.Errorf("wrapped: %w", &VersionYankedError{Product: "tofu", Version: "1.6.1"}),
+			target:   ErrVersionYanked,
+			expected: true,
+		},
+		"vulnerable-version": {
+			err:      &VulnerableVersionError{Product: "tofu", Version: "1.6.0", AdvisoryIDs: []string{"GO-2024-0001"}},
+			target:   ErrVulnerableVersion,
+			expected: true,
+		},
+		"end-of-support-skippable": {
+			err:      SkippableErr(&EndOfSupportError{Product: "tofu", Version: "1.6.0", Cycle: "1.6"}),
+			target:   ErrEndOfSupport,
+			expected: true,
+		},
+		"provenance-invalid": {
+			err:      fmt.Errorf("wrapped: %w", &ProvenanceError{Filename: "tofu.zip", Err: fmt.Errorf("untrusted builder")}),
+			target:   ErrProvenanceInvalid,
+			expected: true,
+		},
+		"skippable": {
+			err:      SkippableErr(&BuildError{Err: fmt.Errorf("exit status 1")}),
+			target:   ErrBuildFailed,
+			expected: true,
+		},
+		"multierror": {
+			err: fmt.Errorf("unable to install from 2 sources: %w",
+				multierror.Append(nil,
+					SkippableErr(&NoBuildError{Product: "tofu"}),
+					&SignatureError{Err: fmt.Errorf("invalid")},
+				)),
+			target:   ErrSignatureInvalid,
+			expected: true,
+		},
+		"multierror-mismatch": {
+			err: fmt.Errorf("unable to install from 1 source: %w",
+				multierror.Append(nil, SkippableErr(&NoBuildError{Product: "tofu"}))),
+			target:   ErrSizeMismatch,
+			expected: false,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if errors.Is(tc.err, tc.target) != tc.expected {
+				t.Fatalf("expected errors.Is(%q, %q) to be %t", tc.err, tc.target, tc.expected)
+			}
+		})
+	}
+}
+
+func TestErrors_As(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", multierror.Append(nil,
+		SkippableErr(&ChecksumMismatchError{Filename: "tofu.zip"})))
+
+	var cmErr *ChecksumMismatchError
+	if !errors.As(err, &cmErr) {
+		t.Fatalf("expected %q to contain ChecksumMismatchError", err)
+	}
+	if cmErr.Filename != "tofu.zip" {
+		t.Fatalf("unexpected filename: %q", cmErr.Filename)
+	}
+
+	if !IsErrorSkippable(err) {
+		t.Fatalf("expected %q to be skippable", err)
+	}
+}
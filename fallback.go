@@ -0,0 +1,161 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package install
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/chushi-io/lf-install/errors"
+)
+
+// FallbackPolicy determines what the Installer does when a source fails,
+// depending on the class of the error.
+//
+// Errors explicitly marked as skippable (see errors.SkippableErr) which
+// don't fall into any of the classes below always lead to the next source.
+// Errors returned from hooks (see HookError) always stop the installation,
+// as does cancellation of the context, regardless of the error.
+type FallbackPolicy struct {
+	// Transient applies to network failures and server errors
+	// (see errors.ErrTransient)
+	Transient FallbackRule
+
+	// NotFound applies when the version or a build for the current platform
+	// doesn't exist (see errors.ErrVersionNotFound, errors.ErrNoBuildForPlatform)
+	NotFound FallbackRule
+
+	// Verification applies to signature, checksum, size and version mismatches
+	// and provenance which cannot be verified.
+	// Continue is ignored, i.e. verification failures are never skipped.
+	Verification FallbackRule
+
+	// Other applies to any other errors
+	Other FallbackRule
+
+	// RetryDelay represents how long to wait between retries
+	RetryDelay time.Duration
+}
+
+// FallbackRule describes how to handle a class of errors
+type FallbackRule struct {
+	// Retries represents how many times to retry the same source
+	Retries int
+
+	// Continue indicates whether to move on to the next source
+	// once retries are exhausted, otherwise the error is returned
+	Continue bool
+}
+
+// DefaultFallbackPolicy moves on to the next source on network failures
+// and missing versions and stops on any other errors
+func DefaultFallbackPolicy() FallbackPolicy {
+	return FallbackPolicy{
+		Transient: FallbackRule{Continue: true},
+		NotFound:  FallbackRule{Continue: true},
+	}
+}
+
+type errorClass int
+
+const (
+	errorClassOther errorClass = iota
+	errorClassSkippable
+	errorClassTransient
+	errorClassNotFound
+	errorClassVerification
+	errorClassHook
+)
+
+func (c errorClass) String() string {
+	switch c {
+	case errorClassSkippable:
+		return "skippable"
+	case errorClassTransient:
+		return "transient"
+	case errorClassNotFound:
+		return "not found"
+	case errorClassVerification:
+		return "verification"
+	case errorClassHook:
+		return "hook"
+	}
+	return "other"
+}
+
+func classifyError(err error) errorClass {
+	var hookErr *HookError
+	switch {
+	case stderrors.As(err, &hookErr):
+		return errorClassHook
+	case stderrors.Is(err, errors.ErrSignatureInvalid),
+		stderrors.Is(err, errors.ErrChecksumMismatch),
+		stderrors.Is(err, errors.ErrSizeMismatch),
+		stderrors.Is(err, errors.ErrVersionMismatch),
+		stderrors.Is(err, errors.ErrProvenanceInvalid):
+		return errorClassVerification
+	case stderrors.Is(err, errors.ErrTransient):
+		return errorClassTransient
+	case stderrors.Is(err, errors.ErrVersionNotFound),
+		stderrors.Is(err, errors.ErrNoBuildForPlatform):
+		return errorClassNotFound
+	case errors.IsErrorSkippable(err):
+		return errorClassSkippable
+	}
+	return errorClassOther
+}
+
+func (fp FallbackPolicy) rule(class errorClass) FallbackRule {
+	switch class {
+	case errorClassSkippable:
+		return FallbackRule{Continue: true}
+	case errorClassTransient:
+		return fp.Transient
+	case errorClassNotFound:
+		return fp.NotFound
+	case errorClassVerification:
+		return FallbackRule{Retries: fp.Verification.Retries}
+	case errorClassHook:
+		return FallbackRule{}
+	}
+	return fp.Other
+}
+
+// run calls f until it succeeds or retries per policy are exhausted
+// and reports whether the next source should be attempted on failure
+func (fp FallbackPolicy) run(ctx context.Context, i *Installer, f func(context.Context) (string, error)) (string, bool, error) {
+	for attempt := 0; ; attempt++ {
+		execPath, err := f(ctx)
+		if err == nil {
+			return execPath, false, nil
+		}
+
+		// interrupted installation (e.g. via Ctrl-C) is never retried
+		// nor continued with the next source, whatever the error
+		if ctx.Err() != nil {
+			return "", false, fmt.Errorf("%w: %w", ctx.Err(), err)
+		}
+
+		class := classifyError(err)
+		rule := fp.rule(class)
+
+		if attempt < rule.Retries {
+			i.logger.Printf("retrying after %s error (attempt %d of %d): %s",
+				class, attempt+1, rule.Retries, err)
+			select {
+			case <-ctx.Done():
+				return "", false, fmt.Errorf("%w: %w", ctx.Err(), err)
+			case <-time.After(fp.RetryDelay):
+			}
+			continue
+		}
+
+		if rule.Continue {
+			i.logger.Printf("skipping source after %s error: %s", class, err)
+		}
+		return "", rule.Continue, err
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package install_test
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	install "github.com/chushi-io/lf-install"
+	"github.com/chushi-io/lf-install/errors"
+	isrc "github.com/chushi-io/lf-install/internal/src"
+	"github.com/chushi-io/lf-install/internal/testutil"
+	"github.com/chushi-io/lf-install/src"
+)
+
+func TestInstaller_Ensure_fallbackPolicy(t *testing.T) {
+	testCases := map[string]struct {
+		policy           *install.FallbackPolicy
+		err              error
+		expectedAttempts int
+		expectFallback   bool
+	}{
+		"default-transient": {
+			err:              &errors.NetworkError{Err: fmt.Errorf("connection refused")},
+			expectedAttempts: 1,
+			expectFallback:   true,
+		},
+		"default-not-found": {
+			err:              &errors.NoBuildError{Product: "tofu"},
+			expectedAttempts: 1,
+			expectFallback:   true,
+		},
+		"default-verification": {
+			err:              &errors.ChecksumMismatchError{},
+			expectedAttempts: 1,
+			expectFallback:   false,
+		},
+		"default-other": {
+			err:              fmt.Errorf("unexpected"),
+			expectedAttempts: 1,
+			expectFallback:   false,
+		},
+		"default-skippable": {
+			err:              errors.SkippableErr(fmt.Errorf("not found in PATH")),
+			expectedAttempts: 1,
+			expectFallback:   true,
+		},
+		"retry-transient-then-stop": {
+			policy: &install.FallbackPolicy{
+				Transient: install.FallbackRule{Retries: 2},
+			},
+			err:              &errors.NetworkError{StatusCode: 502, Err: fmt.Errorf("bad gateway")},
+			expectedAttempts: 3,
+			expectFallback:   false,
+		},
+		"verification-never-skipped": {
+			policy: &install.FallbackPolicy{
+				Verification: install.FallbackRule{Retries: 1, Continue: true},
+			},
+			err:              errors.SkippableErr(&errors.SignatureError{Err: fmt.Errorf("invalid")}),
+			expectedAttempts: 2,
+			expectFallback:   false,
+		},
+		"provenance-never-skipped": {
+			policy: &install.FallbackPolicy{
+				Other: install.FallbackRule{Continue: true},
+			},
+			err:              &errors.ProvenanceError{Filename: "tofu.zip", Err: fmt.Errorf("no attestation")},
+			expectedAttempts: 1,
+			expectFallback:   false,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			i := install.NewInstaller()
+			i.SetLogger(testutil.TestLogger())
+			if tc.policy != nil {
+				i.SetFallbackPolicy(*tc.policy)
+			}
+
+			failing := &fakeFailingInstallable{err: tc.err}
+			fallback := &fakeFailingInstallable{}
+
+			_, err := i.Ensure(context.Background(), []src.Source{failing, fallback})
+			if failing.attempts != tc.expectedAttempts {
+				t.Fatalf("expected %d attempts, got %d", tc.expectedAttempts, failing.attempts)
+			}
+			if tc.expectFallback {
+				if err != nil {
+					t.Fatalf("expected fallback to succeed, got: %s", err)
+				}
+				if fallback.attempts != 1 {
+					t.Fatal("expected fallback source to be used")
+				}
+				return
+			}
+
+			if fallback.attempts != 0 {
+				t.Fatal("expected fallback source not to be used")
+			}
+			if !stderrors.Is(err, tc.err) {
+				t.Fatalf("expected error %q, got %q", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestInstaller_Ensure_fallbackCancelled(t *testing.T) {
+	i := install.NewInstaller()
+	i.SetLogger(testutil.TestLogger())
+	i.SetFallbackPolicy(install.FallbackPolicy{
+		Transient: install.FallbackRule{Retries: 2, Continue: true},
+		Other:     install.FallbackRule{Continue: true},
+	})
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	cancelFunc()
+
+	// cancelled requests surface as network errors without status code
+	sourceErr := &errors.NetworkError{Err: context.Canceled}
+	failing := &fakeFailingInstallable{err: sourceErr}
+	fallback := &fakeFailingInstallable{}
+
+	_, err := i.Ensure(ctx, []src.Source{failing, fallback})
+	if failing.attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", failing.attempts)
+	}
+	if fallback.attempts != 0 {
+		t.Fatal("expected fallback source not to be used")
+	}
+	if !stderrors.Is(err, context.Canceled) || !stderrors.Is(err, sourceErr) {
+		t.Fatalf("expected cancellation wrapping the source error, got %q", err)
+	}
+}
+
+type fakeFailingInstallable struct {
+	err      error
+	attempts int
+}
+
+func (*fakeFailingInstallable) IsSourceImpl() isrc.InstallSrcSigil {
+	return isrc.InstallSrcSigil{}
+}
+
+func (fi *fakeFailingInstallable) Install(ctx context.Context) (string, error) {
+	fi.attempts++
+	if fi.err != nil {
+		return "", fi.err
+	}
+	return "/fake/path", nil
+}
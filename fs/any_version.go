@@ -8,11 +8,13 @@ import (
 	"fmt"
 	"log"
 	"path/filepath"
+	"time"
 
 	"github.com/chushi-io/lf-install/errors"
-	"github.com/chushi-io/lf-install/internal/src"
+	isrc "github.com/chushi-io/lf-install/internal/src"
 	"github.com/chushi-io/lf-install/internal/validators"
 	"github.com/chushi-io/lf-install/product"
+	"github.com/chushi-io/lf-install/src"
 )
 
 // AnyVersion finds an executable binary of any version
@@ -38,11 +40,30 @@ type AnyVersion struct {
 	// conflicts with Product and ExtraPaths
 	ExactBinPath string
 
-	logger *log.Logger
+	// SelectHighest indicates whether to examine every binary found
+	// and pick the highest version (via Product.GetVersion),
+	// instead of the first one (see Candidates)
+	SelectHighest bool
+
+	// Timeout is used when obtaining versions of binaries
+	// with SelectHighest
+	Timeout time.Duration
+
+	// VersionCache (if set) caches versions reported by binaries,
+	// so they're only executed when they change
+	VersionCache *VersionCache
+
+	// VersionDetection determines how versions of binaries are obtained
+	// (executed via Product.GetVersion by default)
+	VersionDetection VersionDetection
+
+	logger     *log.Logger
+	details    src.Details
+	candidates []Candidate
 }
 
-func (*AnyVersion) IsSourceImpl() src.InstallSrcSigil {
-	return src.InstallSrcSigil{}
+func (*AnyVersion) IsSourceImpl() isrc.InstallSrcSigil {
+	return isrc.InstallSrcSigil{}
 }
 
 func (av *AnyVersion) Validate() error {
@@ -58,6 +79,10 @@ func (av *AnyVersion) Validate() error {
 	if av.Product != nil && !validators.IsBinaryNameValid(av.Product.BinaryName()) {
 		return fmt.Errorf("invalid binary name: %q", av.Product.BinaryName())
 	}
+	if av.SelectHighest && (av.Product == nil ||
+		(av.Product.GetVersion == nil && av.VersionDetection != DetectByBuildInfoOnly)) {
+		return fmt.Errorf("SelectHighest requires Product with a version getter")
+	}
 	return nil
 }
 
@@ -79,9 +104,14 @@ func (av *AnyVersion) Find(ctx context.Context) (string, error) {
 			return "", errors.SkippableErr(err)
 		}
 
+		av.details = src.Details{}
 		return av.ExactBinPath, nil
 	}
 
+	if av.SelectHighest {
+		return av.findHighest(ctx)
+	}
+
 	execPath, err := findFile(lookupDirs(av.ExtraPaths), av.Product.BinaryName(), checkExecutable)
 	if err != nil {
 		return "", errors.SkippableErr(err)
@@ -94,5 +124,41 @@ func (av *AnyVersion) Find(ctx context.Context) (string, error) {
 			return "", errors.SkippableErr(err)
 		}
 	}
+
+	av.details = src.Details{}
 	return execPath, nil
 }
+
+func (av *AnyVersion) findHighest(ctx context.Context) (string, error) {
+	timeout := defaultTimeout
+	if av.Timeout > 0 {
+		timeout = av.Timeout
+	}
+	ctx, cancelFunc := context.WithTimeout(ctx, timeout)
+	defer cancelFunc()
+
+	c, candidates, err := findHighestVersion(ctx, av.log(), lookupDirs(av.ExtraPaths),
+		av.Product.BinaryName(), av.VersionCache.getter(av.Product.Name, versionGetterFor(*av.Product, av.VersionDetection)), nil)
+	av.candidates = candidates
+	if err != nil {
+		return "", errors.SkippableErr(err)
+	}
+
+	av.details = src.Details{Version: c.Version}
+	return c.Path, nil
+}
+
+// Candidates returns all binaries considered by the last Find call
+// (only reported when SelectHighest is set)
+func (av *AnyVersion) Candidates() []Candidate {
+	return av.candidates
+}
+
+// Details describes the binary found by the last Find call
+func (av *AnyVersion) Details() src.Details {
+	details := av.details
+	if av.Product != nil {
+		details.Product = av.Product.Name
+	}
+	return details
+}
@@ -55,6 +55,16 @@ func TestAnyVersionValidate(t *testing.T) {
 			},
 			expectedErr: fmt.Errorf("invalid binary name: \"invalid!\""),
 		},
+		"SelectHighest-without-version-getter": {
+			av: AnyVersion{
+				Product: &product.Product{
+					BinaryName: product.OpenTofu.BinaryName,
+					Name:       product.OpenTofu.Name,
+				},
+				SelectHighest: true,
+			},
+			expectedErr: fmt.Errorf("SelectHighest requires Product with a version getter"),
+		},
 		"Product-valid": {
 			av: AnyVersion{
 				Product: &product.OpenTofu,
@@ -0,0 +1,130 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package fs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/hashicorp/go-version"
+)
+
+// Candidate describes a binary considered when searching
+// for the highest version (see SelectHighest)
+type Candidate struct {
+	// Path represents absolute path to the binary as found
+	Path string
+
+	// ResolvedPath represents the path with any symlinks resolved
+	ResolvedPath string
+
+	// Version represents the version reported by the binary (if obtained)
+	Version *version.Version
+
+	// DuplicateOf represents path of an earlier candidate resolving
+	// to the same binary, in which case it wasn't examined again
+	DuplicateOf string
+
+	// Err represents why the candidate was rejected
+	// (nil if it satisfied constraints)
+	Err error
+
+	// Selected indicates whether the candidate was returned
+	Selected bool
+}
+
+func (c Candidate) String() string {
+	switch {
+	case c.DuplicateOf != "":
+		return fmt.Sprintf("%s (duplicate of %s)", c.Path, c.DuplicateOf)
+	case c.Err != nil:
+		return fmt.Sprintf("%s (rejected: %s)", c.Path, c.Err)
+	case c.Selected:
+		return fmt.Sprintf("%s (%s, selected)", c.Path, c.Version)
+	}
+	return fmt.Sprintf("%s (%s)", c.Path, c.Version)
+}
+
+type versionGetter func(ctx context.Context, execPath string) (*version.Version, error)
+
+// versionCheck returns an error if the version is not acceptable
+type versionCheck func(v *version.Version) error
+
+// findHighestVersion examines every executable binary found in dirs
+// and returns the one with the highest version passing check
+// (nil check accepts any version). Binaries which resolve
+// to the same file are examined only once. All candidates are returned
+// regardless of whether any passes the check.
+func findHighestVersion(ctx context.Context, logger *log.Logger, dirs []string, binaryName string,
+	getVersion versionGetter, check versionCheck) (*Candidate, []Candidate, error) {
+	paths := findFiles(dirs, binaryName, checkExecutable)
+	if len(paths) == 0 {
+		return nil, nil, fmt.Errorf("%s: %w", binaryName, exec.ErrNotFound)
+	}
+
+	candidates := make([]Candidate, 0, len(paths))
+	seen := make(map[string]string, 0)
+	selected := -1
+
+	for _, path := range paths {
+		c := Candidate{Path: path}
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			c.Err = err
+			candidates = append(candidates, c)
+			continue
+		}
+		c.Path = absPath
+
+		c.ResolvedPath, err = filepath.EvalSymlinks(absPath)
+		if err != nil {
+			c.Err = err
+			candidates = append(candidates, c)
+			continue
+		}
+		if firstPath, ok := seen[c.ResolvedPath]; ok {
+			c.DuplicateOf = firstPath
+			candidates = append(candidates, c)
+			continue
+		}
+		seen[c.ResolvedPath] = c.Path
+
+		c.Version, err = getVersion(ctx, c.Path)
+		if err != nil {
+			c.Err = fmt.Errorf("unable to obtain version: %w", err)
+			candidates = append(candidates, c)
+			continue
+		}
+
+		if check != nil {
+			c.Err = check(c.Version)
+			if c.Err != nil {
+				candidates = append(candidates, c)
+				continue
+			}
+		}
+
+		candidates = append(candidates, c)
+		if selected < 0 || c.Version.GreaterThan(candidates[selected].Version) {
+			selected = len(candidates) - 1
+		}
+	}
+
+	if selected >= 0 {
+		candidates[selected].Selected = true
+	}
+	for _, c := range candidates {
+		logger.Printf("considered candidate %s", c)
+	}
+
+	if selected < 0 {
+		return nil, candidates, fmt.Errorf("none of %d candidates of %s satisfies constraints",
+			len(candidates), binaryName)
+	}
+
+	return &candidates[selected], candidates, nil
+}
@@ -11,9 +11,10 @@ import (
 	"time"
 
 	"github.com/chushi-io/lf-install/errors"
-	"github.com/chushi-io/lf-install/internal/src"
+	isrc "github.com/chushi-io/lf-install/internal/src"
 	"github.com/chushi-io/lf-install/internal/validators"
 	"github.com/chushi-io/lf-install/product"
+	"github.com/chushi-io/lf-install/src"
 	"github.com/hashicorp/go-version"
 )
 
@@ -26,11 +27,24 @@ type ExactVersion struct {
 	ExtraPaths []string
 	Timeout    time.Duration
 
-	logger *log.Logger
+	// VersionCache (if set) caches versions reported by binaries,
+	// so they're only executed when they change
+	VersionCache *VersionCache
+
+	// VersionDetection determines how versions of binaries are obtained
+	// (executed via Product.GetVersion by default)
+	VersionDetection VersionDetection
+
+	// EndOfSupport determines whether to warn about or skip binaries
+	// past end of support per Product.Lifecycle (ignored by default)
+	EndOfSupport product.EndOfSupportAction
+
+	logger  *log.Logger
+	details src.Details
 }
 
-func (*ExactVersion) IsSourceImpl() src.InstallSrcSigil {
-	return src.InstallSrcSigil{}
+func (*ExactVersion) IsSourceImpl() isrc.InstallSrcSigil {
+	return isrc.InstallSrcSigil{}
 }
 
 func (ev *ExactVersion) SetLogger(logger *log.Logger) {
@@ -51,7 +65,7 @@ func (ev *ExactVersion) Validate() error {
 	if ev.Version == nil {
 		return fmt.Errorf("undeclared version")
 	}
-	if ev.Product.GetVersion == nil {
+	if ev.Product.GetVersion == nil && ev.VersionDetection != DetectByBuildInfoOnly {
 		return fmt.Errorf("undeclared version getter")
 	}
 	return nil
@@ -65,13 +79,15 @@ func (ev *ExactVersion) Find(ctx context.Context) (string, error) {
 	ctx, cancelFunc := context.WithTimeout(ctx, timeout)
 	defer cancelFunc()
 
+	getVersion := ev.VersionCache.getter(ev.Product.Name, versionGetterFor(ev.Product, ev.VersionDetection))
+
 	execPath, err := findFile(lookupDirs(ev.ExtraPaths), ev.Product.BinaryName(), func(file string) error {
 		err := checkExecutable(file)
 		if err != nil {
 			return err
 		}
 
-		v, err := ev.Product.GetVersion(ctx, file)
+		v, err := getVersion(ctx, file)
 		if err != nil {
 			return err
 		}
@@ -94,5 +110,18 @@ func (ev *ExactVersion) Find(ctx context.Context) (string, error) {
 		}
 	}
 
+	err = product.CheckEndOfSupport(ev.log(), ev.Product, ev.Version, ev.EndOfSupport)
+	if err != nil {
+		return "", errors.SkippableErr(err)
+	}
+
+	ev.details = src.Details{Version: ev.Version}
 	return execPath, nil
 }
+
+// Details describes the binary found by the last Find call
+func (ev *ExactVersion) Details() src.Details {
+	details := ev.details
+	details.Product = ev.Product.Name
+	return details
+}
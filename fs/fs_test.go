@@ -18,12 +18,20 @@ import (
 var (
 	_ src.Findable       = &AnyVersion{}
 	_ src.LoggerSettable = &AnyVersion{}
+	_ src.Describable    = &AnyVersion{}
 
 	_ src.Findable       = &ExactVersion{}
 	_ src.LoggerSettable = &ExactVersion{}
+	_ src.Describable    = &ExactVersion{}
 
 	_ src.Findable       = &Version{}
 	_ src.LoggerSettable = &Version{}
+	_ src.Describable    = &Version{}
+
+	_ src.Findable       = &ManagedVersion{}
+	_ src.LoggerSettable = &ManagedVersion{}
+	_ src.Validatable    = &ManagedVersion{}
+	_ src.Describable    = &ManagedVersion{}
 )
 
 func TestExactVersion(t *testing.T) {
@@ -8,13 +8,16 @@ package fs
 
 import (
 	"context"
+	stderrors "errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/chushi-io/lf-install/errors"
 	"github.com/chushi-io/lf-install/internal/testutil"
 	"github.com/chushi-io/lf-install/product"
+	"github.com/hashicorp/go-version"
 )
 
 func TestAnyVersion_notExecutable(t *testing.T) {
@@ -102,3 +105,209 @@ func TestAnyVersion_exactBinPath_notExecutable(t *testing.T) {
 		t.Fatalf("expected a skippable error, got: %#v", err)
 	}
 }
+
+func TestVersion_selectHighest(t *testing.T) {
+	binaryName := "tool"
+	dirs := make([]string, 4)
+	for i := range dirs {
+		dirs[i] = t.TempDir()
+	}
+	writeExecutable(t, filepath.Join(dirs[0], binaryName), "1.2.0")
+	err := os.Symlink(filepath.Join(dirs[0], binaryName), filepath.Join(dirs[1], binaryName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeExecutable(t, filepath.Join(dirs[2], binaryName), "1.5.0")
+	writeExecutable(t, filepath.Join(dirs[3], binaryName), "2.0.0")
+
+	t.Setenv("PATH", strings.Join(dirs[:3], string(os.PathListSeparator)))
+
+	v := &Version{
+		Product: product.Product{
+			BinaryName: func() string { return binaryName },
+			GetVersion: func(ctx context.Context, execPath string) (*version.Version, error) {
+				b, err := os.ReadFile(execPath)
+				if err != nil {
+					return nil, err
+				}
+				return version.NewVersion(string(b))
+			},
+		},
+		Constraints:   version.MustConstraints(version.NewConstraint("< 2.0")),
+		ExtraPaths:    dirs[3:],
+		SelectHighest: true,
+	}
+	v.SetLogger(testutil.TestLogger())
+
+	execPath, err := v.Find(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if execPath != filepath.Join(dirs[2], binaryName) {
+		t.Fatalf("unexpected path selected: %s", execPath)
+	}
+	if v.Details().Version.String() != "1.5.0" {
+		t.Fatalf("unexpected version: %s", v.Details().Version)
+	}
+
+	candidates := v.Candidates()
+	if len(candidates) != 4 {
+		t.Fatalf("expected 4 candidates, got %q", candidates)
+	}
+	if candidates[1].DuplicateOf != candidates[0].Path {
+		t.Fatalf("expected symlink to be reported as duplicate, got %s", candidates[1])
+	}
+	if candidates[3].Err == nil {
+		t.Fatalf("expected version not meeting constraints to be rejected, got %s", candidates[3])
+	}
+	if !candidates[2].Selected {
+		t.Fatalf("expected candidate to be selected, got %s", candidates[2])
+	}
+}
+
+func TestVersion_endOfSupport(t *testing.T) {
+	dir := t.TempDir()
+	writeExecutable(t, filepath.Join(dir, "tool"), "1.6.2")
+	t.Setenv("PATH", dir)
+
+	lc, err := product.ParseLifecycle(strings.NewReader(
+		`{"cycles": [{"cycle": "1.6", "end_of_support": "2025-01-09"}, {"cycle": "1.8", "end_of_support": "2025-01-09"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := &Version{
+		Product: product.Product{
+			Name:       "tool",
+			BinaryName: func() string { return "tool" },
+			GetVersion: func(ctx context.Context, execPath string) (*version.Version, error) {
+				b, err := os.ReadFile(execPath)
+				if err != nil {
+					return nil, err
+				}
+				return version.NewVersion(string(b))
+			},
+			Lifecycle: lc,
+		},
+		Constraints: version.MustConstraints(version.NewConstraint("~> 1.6")),
+	}
+	v.SetLogger(testutil.TestLogger())
+
+	v.EndOfSupport = product.EndOfSupportWarn
+	if _, err := v.Find(context.Background()); err != nil {
+		t.Fatalf("expected binary to be found with a warning, got %s", err)
+	}
+
+	v.EndOfSupport = product.EndOfSupportFail
+	_, err = v.Find(context.Background())
+	if !errors.IsErrorSkippable(err) || !stderrors.Is(err, errors.ErrEndOfSupport) {
+		t.Fatalf("expected skippable end of support error, got %v", err)
+	}
+
+	// binaries past end of support are skipped in favour of supported ones
+	supportedDir, unsupportedDir := t.TempDir(), t.TempDir()
+	writeExecutable(t, filepath.Join(supportedDir, "tool"), "1.7.0")
+	writeExecutable(t, filepath.Join(unsupportedDir, "tool"), "1.8.0")
+	t.Setenv("PATH", strings.Join([]string{dir, supportedDir, unsupportedDir}, string(filepath.ListSeparator)))
+
+	for _, selectHighest := range []bool{false, true} {
+		v.SelectHighest = selectHighest
+		execPath, err := v.Find(context.Background())
+		if err != nil {
+			t.Fatalf("select highest %t: %s", selectHighest, err)
+		}
+		if execPath != filepath.Join(supportedDir, "tool") {
+			t.Fatalf("select highest %t: expected supported binary to be found, got %s", selectHighest, execPath)
+		}
+		if v.Details().Version.String() != "1.7.0" {
+			t.Fatalf("select highest %t: unexpected version: %s", selectHighest, v.Details().Version)
+		}
+	}
+}
+
+func writeExecutable(t *testing.T, path, content string) {
+	t.Helper()
+	err := os.WriteFile(path, []byte(content), 0o700)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManagedVersion(t *testing.T) {
+	homeDir := t.TempDir()
+	for _, key := range []string{"TOFUENV_ROOT", "ASDF_DATA_DIR", "MISE_DATA_DIR", "AQUA_ROOT_DIR", "XDG_DATA_HOME"} {
+		t.Setenv(key, "")
+	}
+
+	installs := map[string]string{
+		".tofuenv/versions/1.6.0/tofu":                       "",
+		".tofuenv/versions/latest/tofu":                      "",
+		".asdf/installs/opentofu/1.7.1/bin/tofu":             "",
+		".local/share/mise/installs/opentofu/1.9.0/bin/tofu": "",
+		".local/share/aquaproj-aqua/pkgs/github_release/github.com/opentofu/opentofu/v1.7.3/tofu_1.7.3_linux_amd64.zip/tofu": "",
+	}
+	for path := range installs {
+		fullPath := filepath.Join(homeDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeExecutable(t, fullPath, "")
+	}
+
+	testCases := map[string]struct {
+		constraints     string
+		managers        []VersionManager
+		expectedVersion string
+		expectedPath    string
+	}{
+		"highest": {
+			expectedVersion: "1.9.0",
+			expectedPath:    ".local/share/mise/installs/opentofu/1.9.0/bin/tofu",
+		},
+		"constrained": {
+			constraints:     "< 1.8",
+			expectedVersion: "1.7.3",
+			expectedPath:    ".local/share/aquaproj-aqua/pkgs/github_release/github.com/opentofu/opentofu/v1.7.3/tofu_1.7.3_linux_amd64.zip/tofu",
+		},
+		"managers": {
+			managers:        []VersionManager{Tofuenv, Asdf},
+			expectedVersion: "1.7.1",
+			expectedPath:    ".asdf/installs/opentofu/1.7.1/bin/tofu",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mv := &ManagedVersion{
+				Product:  product.OpenTofu,
+				Managers: tc.managers,
+				HomeDir:  homeDir,
+			}
+			if tc.constraints != "" {
+				mv.Constraints = version.MustConstraints(version.NewConstraint(tc.constraints))
+			}
+			mv.SetLogger(testutil.TestLogger())
+
+			execPath, err := mv.Find(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if execPath != filepath.Join(homeDir, filepath.FromSlash(tc.expectedPath)) {
+				t.Fatalf("unexpected path: %s", execPath)
+			}
+			if mv.Details().Version.String() != tc.expectedVersion {
+				t.Fatalf("expected version %s, got %s", tc.expectedVersion, mv.Details().Version)
+			}
+		})
+	}
+
+	mv := &ManagedVersion{
+		Product:     product.OpenTofu,
+		Constraints: version.MustConstraints(version.NewConstraint("< 1.0")),
+		HomeDir:     homeDir,
+	}
+	_, err := mv.Find(context.Background())
+	if !errors.IsErrorSkippable(err) {
+		t.Fatalf("expected a skippable error, got: %#v", err)
+	}
+}
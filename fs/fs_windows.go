@@ -31,6 +31,18 @@ func findFile(dirs []string, file string, f fileCheckFunc) (string, error) {
 	return "", fmt.Errorf("%s: %w", file, exec.ErrNotFound)
 }
 
+// findFiles returns paths to all files in dirs which pass f
+func findFiles(dirs []string, file string, f fileCheckFunc) []string {
+	paths := make([]string, 0)
+	for _, dir := range dirs {
+		path := filepath.Join(dir, file)
+		if err := f(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
 func checkExecutable(file string) error {
 	var exts []string
 	x := os.Getenv(`PATHEXT`)
@@ -0,0 +1,301 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package fs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chushi-io/lf-install/errors"
+	isrc "github.com/chushi-io/lf-install/internal/src"
+	"github.com/chushi-io/lf-install/internal/validators"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/chushi-io/lf-install/src"
+	"github.com/hashicorp/go-version"
+)
+
+// VersionManager represents a tool which manages multiple
+// installed versions of a product
+type VersionManager int
+
+const (
+	// Tofuenv looks in $TOFUENV_ROOT/versions (~/.tofuenv/versions by default)
+	Tofuenv VersionManager = iota
+
+	// Asdf looks in $ASDF_DATA_DIR/installs/<tool> (~/.asdf by default)
+	Asdf
+
+	// Mise looks in $MISE_DATA_DIR/installs/<tool>
+	// ($XDG_DATA_HOME/mise or ~/.local/share/mise by default)
+	Mise
+
+	// Aqua looks in $AQUA_ROOT_DIR/pkgs/github_release/<package>
+	// ($XDG_DATA_HOME/aquaproj-aqua or ~/.local/share/aquaproj-aqua by default)
+	Aqua
+)
+
+func (vm VersionManager) String() string {
+	switch vm {
+	case Tofuenv:
+		return "tofuenv"
+	case Asdf:
+		return "asdf"
+	case Mise:
+		return "mise"
+	case Aqua:
+		return "aqua"
+	}
+	return fmt.Sprintf("VersionManager(%d)", int(vm))
+}
+
+// ManagedVersion finds the highest version of the product installed by
+// version managers (tofuenv, asdf, mise, aqua) which matches Constraints.
+//
+// Versions are read from the directory layout of each manager,
+// so binaries are not executed.
+type ManagedVersion struct {
+	Product product.Product
+
+	// Constraints represents constraints the version must match
+	// (any version is matched if empty)
+	Constraints version.Constraints
+
+	// Managers represents version managers to look into,
+	// in order of preference for the same version (all by default)
+	Managers []VersionManager
+
+	// ToolName represents the name under which asdf and mise install
+	// the product (e.g. "opentofu"), defaults to a well-known name
+	// for known products, or the product name
+	ToolName string
+
+	// AquaPackage represents the aqua package of the product
+	// (e.g. "github.com/opentofu/opentofu"), aqua is skipped if unknown
+	AquaPackage string
+
+	// HomeDir overrides the user's home directory
+	HomeDir string
+
+	// EndOfSupport determines whether to warn about or skip binaries
+	// past end of support per Product.Lifecycle (ignored by default)
+	EndOfSupport product.EndOfSupportAction
+
+	logger  *log.Logger
+	details src.Details
+}
+
+// managedTools maps product names to names and packages
+// used by version managers
+var managedTools = map[string]struct {
+	toolName    string
+	aquaPackage string
+}{
+	product.OpenTofu.Name: {toolName: "opentofu", aquaPackage: "github.com/opentofu/opentofu"},
+	product.OpenBao.Name:  {toolName: "openbao", aquaPackage: "github.com/openbao/openbao"},
+}
+
+func (*ManagedVersion) IsSourceImpl() isrc.InstallSrcSigil {
+	return isrc.InstallSrcSigil{}
+}
+
+func (mv *ManagedVersion) SetLogger(logger *log.Logger) {
+	mv.logger = logger
+}
+
+func (mv *ManagedVersion) log() *log.Logger {
+	if mv.logger == nil {
+		return discardLogger
+	}
+	return mv.logger
+}
+
+func (mv *ManagedVersion) Validate() error {
+	if mv.Product.BinaryName == nil || !validators.IsBinaryNameValid(mv.Product.BinaryName()) {
+		return fmt.Errorf("invalid binary name")
+	}
+	for _, m := range mv.Managers {
+		if m < Tofuenv || m > Aqua {
+			return fmt.Errorf("unknown version manager: %s", m)
+		}
+	}
+	return nil
+}
+
+// managedInstall represents a version installed by a version manager
+type managedInstall struct {
+	manager VersionManager
+	version *version.Version
+	dir     string
+}
+
+func (mv *ManagedVersion) Find(ctx context.Context) (string, error) {
+	homeDir := mv.HomeDir
+	if homeDir == "" {
+		var err error
+		homeDir, err = os.UserHomeDir()
+		if err != nil {
+			return "", errors.SkippableErr(err)
+		}
+	}
+
+	managers := mv.Managers
+	if len(managers) == 0 {
+		managers = []VersionManager{Tofuenv, Asdf, Mise, Aqua}
+	}
+
+	binaryName := mv.Product.BinaryName()
+
+	var found *managedInstall
+	var execPath string
+	for _, m := range managers {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		root, binPatterns, ok := mv.layout(m, homeDir, binaryName)
+		if !ok {
+			continue
+		}
+
+		for _, mi := range listManagedInstalls(m, root) {
+			if len(mv.Constraints) > 0 && !mv.Constraints.Check(mi.version) {
+				continue
+			}
+			if found != nil && !mi.version.GreaterThan(found.version) {
+				continue
+			}
+
+			path, ok := findManagedBinary(mi.dir, binPatterns)
+			if !ok {
+				mv.log().Printf("no executable %s found in %s", binaryName, mi.dir)
+				continue
+			}
+
+			mi := mi
+			found, execPath = &mi, path
+		}
+	}
+
+	if found == nil {
+		return "", errors.SkippableErr(fmt.Errorf("no version of %s matching %q installed by %s",
+			binaryName, mv.Constraints, managers))
+	}
+	mv.log().Printf("found %s %s installed by %s at %s",
+		binaryName, found.version, found.manager, execPath)
+
+	err := product.CheckEndOfSupport(mv.log(), mv.Product, found.version, mv.EndOfSupport)
+	if err != nil {
+		return "", errors.SkippableErr(err)
+	}
+
+	mv.details = src.Details{Version: found.version}
+	return execPath, nil
+}
+
+// layout returns the directory containing version directories
+// and patterns of binary paths relative to each version directory
+func (mv *ManagedVersion) layout(m VersionManager, homeDir, binaryName string) (string, []string, bool) {
+	toolName, aquaPackage := mv.ToolName, mv.AquaPackage
+	if tool, ok := managedTools[mv.Product.Name]; ok {
+		if toolName == "" {
+			toolName = tool.toolName
+		}
+		if aquaPackage == "" {
+			aquaPackage = tool.aquaPackage
+		}
+	}
+	if toolName == "" {
+		toolName = mv.Product.Name
+	}
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(homeDir, ".local", "share")
+	}
+
+	switch m {
+	case Tofuenv:
+		if mv.Product.Name != product.OpenTofu.Name {
+			return "", nil, false
+		}
+		root := envOrDefault("TOFUENV_ROOT", filepath.Join(homeDir, ".tofuenv"))
+		return filepath.Join(root, "versions"), []string{binaryName}, true
+	case Asdf:
+		root := envOrDefault("ASDF_DATA_DIR", filepath.Join(homeDir, ".asdf"))
+		return filepath.Join(root, "installs", toolName), []string{filepath.Join("bin", binaryName)}, true
+	case Mise:
+		root := envOrDefault("MISE_DATA_DIR", filepath.Join(dataHome, "mise"))
+		return filepath.Join(root, "installs", toolName),
+			[]string{filepath.Join("bin", binaryName), binaryName}, true
+	case Aqua:
+		if aquaPackage == "" {
+			return "", nil, false
+		}
+		root := envOrDefault("AQUA_ROOT_DIR", filepath.Join(dataHome, "aquaproj-aqua"))
+		// e.g. v1.6.0/tofu_1.6.0_linux_amd64.zip/tofu
+		return filepath.Join(root, "pkgs", "github_release", filepath.FromSlash(aquaPackage)),
+			[]string{filepath.Join("*", binaryName)}, true
+	}
+
+	return "", nil, false
+}
+
+// listManagedInstalls returns installs found in root,
+// skipping any directories not named after a version
+func listManagedInstalls(m VersionManager, root string) []managedInstall {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+
+	installs := make([]managedInstall, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		v, err := version.NewVersion(strings.TrimPrefix(entry.Name(), "v"))
+		if err != nil {
+			continue
+		}
+		installs = append(installs, managedInstall{
+			manager: m,
+			version: v,
+			dir:     filepath.Join(root, entry.Name()),
+		})
+	}
+	return installs
+}
+
+func findManagedBinary(dir string, patterns []string) (string, bool) {
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			continue
+		}
+		for _, path := range matches {
+			if checkExecutable(path) == nil {
+				return path, true
+			}
+		}
+	}
+	return "", false
+}
+
+func envOrDefault(key, defaultValue string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return defaultValue
+}
+
+// Details describes the binary found by the last Find call
+func (mv *ManagedVersion) Details() src.Details {
+	details := mv.details
+	details.Product = mv.Product.Name
+	return details
+}
@@ -5,15 +5,17 @@ package fs
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"log"
 	"path/filepath"
 	"time"
 
 	"github.com/chushi-io/lf-install/errors"
-	"github.com/chushi-io/lf-install/internal/src"
+	isrc "github.com/chushi-io/lf-install/internal/src"
 	"github.com/chushi-io/lf-install/internal/validators"
 	"github.com/chushi-io/lf-install/product"
+	"github.com/chushi-io/lf-install/src"
 	"github.com/hashicorp/go-version"
 )
 
@@ -26,11 +28,30 @@ type Version struct {
 	ExtraPaths  []string
 	Timeout     time.Duration
 
-	logger *log.Logger
+	// SelectHighest indicates whether to examine every binary found
+	// and pick the highest version matching the constraint,
+	// instead of the first one (see Candidates)
+	SelectHighest bool
+
+	// VersionCache (if set) caches versions reported by binaries,
+	// so they're only executed when they change
+	VersionCache *VersionCache
+
+	// VersionDetection determines how versions of binaries are obtained
+	// (executed via Product.GetVersion by default)
+	VersionDetection VersionDetection
+
+	// EndOfSupport determines whether to warn about or skip binaries
+	// past end of support per Product.Lifecycle (ignored by default)
+	EndOfSupport product.EndOfSupportAction
+
+	logger     *log.Logger
+	details    src.Details
+	candidates []Candidate
 }
 
-func (*Version) IsSourceImpl() src.InstallSrcSigil {
-	return src.InstallSrcSigil{}
+func (*Version) IsSourceImpl() isrc.InstallSrcSigil {
+	return isrc.InstallSrcSigil{}
 }
 
 func (v *Version) SetLogger(logger *log.Logger) {
@@ -51,7 +72,7 @@ func (v *Version) Validate() error {
 	if len(v.Constraints) == 0 {
 		return fmt.Errorf("undeclared version constraints")
 	}
-	if v.Product.GetVersion == nil {
+	if v.Product.GetVersion == nil && v.VersionDetection != DetectByBuildInfoOnly {
 		return fmt.Errorf("undeclared version getter")
 	}
 	return nil
@@ -65,26 +86,50 @@ func (v *Version) Find(ctx context.Context) (string, error) {
 	ctx, cancelFunc := context.WithTimeout(ctx, timeout)
 	defer cancelFunc()
 
+	getVersion := v.VersionCache.getter(v.Product.Name, versionGetterFor(v.Product, v.VersionDetection))
+
+	if v.SelectHighest {
+		c, candidates, err := findHighestVersion(ctx, v.log(), lookupDirs(v.ExtraPaths),
+			v.Product.BinaryName(), getVersion, v.checkVersion)
+		v.candidates = candidates
+		if err != nil {
+			return "", errors.SkippableErr(err)
+		}
+
+		v.details = src.Details{Version: c.Version}
+		return c.Path, nil
+	}
+
+	var foundVersion *version.Version
+	var eosErr error
 	execPath, err := findFile(lookupDirs(v.ExtraPaths), v.Product.BinaryName(), func(file string) error {
 		err := checkExecutable(file)
 		if err != nil {
 			return err
 		}
 
-		ver, err := v.Product.GetVersion(ctx, file)
+		ver, err := getVersion(ctx, file)
 		if err != nil {
 			return err
 		}
 
-		for _, vc := range v.Constraints {
-			if !vc.Check(ver) {
-				return fmt.Errorf("version (%s) doesn't meet constraints %s", ver, vc.String())
+		err = v.checkVersion(ver)
+		if err != nil {
+			if stderrors.Is(err, errors.ErrEndOfSupport) {
+				eosErr = err
 			}
+			return err
 		}
 
+		foundVersion = ver
+
 		return nil
 	})
 	if err != nil {
+		if eosErr != nil {
+			// only binaries past end of support were found
+			return "", errors.SkippableErr(eosErr)
+		}
 		return "", errors.SkippableErr(err)
 	}
 
@@ -96,5 +141,31 @@ func (v *Version) Find(ctx context.Context) (string, error) {
 		}
 	}
 
+	v.details = src.Details{Version: foundVersion}
 	return execPath, nil
 }
+
+// checkVersion rejects versions not meeting the constraints
+// or past end of support (when EndOfSupport fails), so that
+// the search continues with the next binary
+func (v *Version) checkVersion(ver *version.Version) error {
+	for _, vc := range v.Constraints {
+		if !vc.Check(ver) {
+			return fmt.Errorf("version (%s) doesn't meet constraints %s", ver, vc.String())
+		}
+	}
+	return product.CheckEndOfSupport(v.log(), v.Product, ver, v.EndOfSupport)
+}
+
+// Candidates returns all binaries considered by the last Find call
+// (only reported when SelectHighest is set)
+func (v *Version) Candidates() []Candidate {
+	return v.candidates
+}
+
+// Details describes the binary found by the last Find call
+func (v *Version) Details() src.Details {
+	details := v.details
+	details.Product = v.Product.Name
+	return details
+}
@@ -0,0 +1,193 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package fs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/chushi-io/lf-install/internal/lockfile"
+	"github.com/hashicorp/go-version"
+)
+
+// versionCacheLockTimeout caps how long to wait for other processes
+// to persist the cache before giving up on persisting it
+const versionCacheLockTimeout = 5 * time.Second
+
+// VersionCache persists versions reported by binaries, such that
+// they don't need to be executed again until they change.
+//
+// Entries are keyed by product name and absolute path, and are
+// invalidated when inode, size or modification time of the binary
+// differ from what was recorded.
+//
+// The cache is safe for concurrent use, including by processes
+// sharing the file, which is locked via a .lock file next to it
+// while being written.
+type VersionCache struct {
+	path string
+
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string]versionCacheEntry
+}
+
+type versionCacheEntry struct {
+	Inode   uint64 `json:"inode"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mod_time"`
+	Version string `json:"version"`
+}
+
+// NewVersionCache returns a cache persisted in the file at path
+func NewVersionCache(path string) *VersionCache {
+	return &VersionCache{path: path}
+}
+
+// DefaultVersionCache returns a cache persisted
+// in the user's cache directory
+func DefaultVersionCache() (*VersionCache, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewVersionCache(filepath.Join(cacheDir, "lf-install", "versions.json")), nil
+}
+
+// getter wraps getVersion of the given product to consult the cache first.
+// A nil cache returns getVersion unchanged.
+func (vc *VersionCache) getter(productName string, getVersion versionGetter) versionGetter {
+	if vc == nil {
+		return getVersion
+	}
+	return func(ctx context.Context, execPath string) (*version.Version, error) {
+		return vc.getVersion(ctx, productName, execPath, getVersion)
+	}
+}
+
+func (vc *VersionCache) getVersion(ctx context.Context, productName, execPath string, getVersion versionGetter) (*version.Version, error) {
+	absPath, err := filepath.Abs(execPath)
+	if err != nil {
+		return getVersion(ctx, execPath)
+	}
+	fi, err := os.Stat(absPath)
+	if err != nil {
+		return getVersion(ctx, execPath)
+	}
+
+	key := productName + "\x00" + absPath
+	entry := versionCacheEntry{
+		Inode:   fileInode(fi),
+		Size:    fi.Size(),
+		ModTime: fi.ModTime().UnixNano(),
+	}
+
+	vc.mu.Lock()
+	vc.load()
+	cached, ok := vc.entries[key]
+	vc.mu.Unlock()
+	if ok && cached.Inode == entry.Inode &&
+		cached.Size == entry.Size && cached.ModTime == entry.ModTime {
+		v, err := version.NewVersion(cached.Version)
+		if err == nil {
+			return v, nil
+		}
+	}
+
+	// the binary is executed without holding the lock,
+	// so that lookups of other binaries can proceed meanwhile
+	v, err := getVersion(ctx, execPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Version = v.Original()
+	// the cache is only an optimization, failing to persist it is not fatal
+	_ = vc.store(ctx, key, entry)
+
+	return v, nil
+}
+
+// load reads entries from the file, if it wasn't read yet
+func (vc *VersionCache) load() {
+	if vc.loaded {
+		return
+	}
+	vc.loaded = true
+	vc.entries = vc.read()
+}
+
+// read returns entries persisted in the file
+func (vc *VersionCache) read() map[string]versionCacheEntry {
+	entries := make(map[string]versionCacheEntry)
+
+	b, err := os.ReadFile(vc.path)
+	if err != nil {
+		return entries
+	}
+	// a corrupted cache is treated as empty and overwritten on save
+	_ = json.Unmarshal(b, &entries)
+
+	return entries
+}
+
+// store records the entry and persists the cache. The file is locked
+// while entries persisted by other processes since the cache was loaded
+// are merged, so that concurrent processes don't discard each other's entries.
+func (vc *VersionCache) store(ctx context.Context, key string, entry versionCacheEntry) error {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	vc.entries[key] = entry
+
+	ctx, cancelFunc := context.WithTimeout(ctx, versionCacheLockTimeout)
+	defer cancelFunc()
+	lock, err := lockfile.Acquire(ctx, vc.path+".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	for k, e := range vc.read() {
+		if k != key {
+			vc.entries[k] = e
+		}
+	}
+
+	return vc.save()
+}
+
+func (vc *VersionCache) save() error {
+	b, err := json.Marshal(vc.entries)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(vc.path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	// write via temp file & rename, so that concurrent
+	// readers never observe a partially written cache
+	f, err := os.CreateTemp(filepath.Dir(vc.path), filepath.Base(vc.path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("unable to write version cache: %w", err)
+	}
+
+	return os.Rename(f.Name(), vc.path)
+}
@@ -0,0 +1,158 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package fs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-version"
+)
+
+func TestVersionCache(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	binPath := filepath.Join(dir, "tool")
+	cachePath := filepath.Join(dir, "cache", "versions.json")
+
+	err := os.WriteFile(binPath, []byte("1.0.0"), 0o700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	getVersion := func(ctx context.Context, execPath string) (*version.Version, error) {
+		calls++
+		b, err := os.ReadFile(execPath)
+		if err != nil {
+			return nil, err
+		}
+		return version.NewVersion(string(b))
+	}
+
+	assertVersion := func(t *testing.T, vc *VersionCache, expectedVersion string, expectedCalls int) {
+		t.Helper()
+		v, err := vc.getter("tool", getVersion)(ctx, binPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.String() != expectedVersion {
+			t.Fatalf("expected version %s, got %s", expectedVersion, v)
+		}
+		if calls != expectedCalls {
+			t.Fatalf("expected %d calls of version getter, got %d", expectedCalls, calls)
+		}
+	}
+
+	vc := NewVersionCache(cachePath)
+	assertVersion(t, vc, "1.0.0", 1)
+	assertVersion(t, vc, "1.0.0", 1)
+
+	// cache is persisted
+	assertVersion(t, NewVersionCache(cachePath), "1.0.0", 1)
+
+	// cache is invalidated when the binary changes
+	err = os.WriteFile(binPath, []byte("1.10.0"), 0o700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertVersion(t, vc, "1.10.0", 2)
+	assertVersion(t, NewVersionCache(cachePath), "1.10.0", 2)
+
+	// nil cache always calls the getter
+	var nilCache *VersionCache
+	assertVersion(t, nilCache, "1.10.0", 3)
+}
+
+func TestVersionCache_concurrent(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cachePath := filepath.Join(dir, "versions.json")
+
+	binPaths := make([]string, 3)
+	for i := range binPaths {
+		binPaths[i] = filepath.Join(dir, fmt.Sprintf("tool%d", i))
+		err := os.WriteFile(binPaths[i], []byte(fmt.Sprintf("1.%d.0", i)), 0o700)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	readVersion := func(ctx context.Context, execPath string) (*version.Version, error) {
+		b, err := os.ReadFile(execPath)
+		if err != nil {
+			return nil, err
+		}
+		return version.NewVersion(string(b))
+	}
+
+	// stale represents another process which loaded the cache
+	// before any entries were persisted
+	stale := NewVersionCache(cachePath)
+	_, err := stale.getter("tool", readVersion)(ctx, filepath.Join(dir, "missing"))
+	if err == nil {
+		t.Fatal("expected error for missing binary")
+	}
+
+	// each getter waits for the other one to be called, which
+	// only happens if binaries are not executed one at a time
+	var started sync.WaitGroup
+	started.Add(2)
+	waitingGetter := func(ctx context.Context, execPath string) (*version.Version, error) {
+		started.Done()
+		done := make(chan struct{})
+		go func() {
+			started.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			return nil, fmt.Errorf("version getters were serialized")
+		}
+		return readVersion(ctx, execPath)
+	}
+
+	shared := NewVersionCache(cachePath)
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, binPath := range binPaths[:2] {
+		i, binPath := i, binPath
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = shared.getter("tool", waitingGetter)(ctx, binPath)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err = stale.getter("tool", readVersion)(ctx, binPaths[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// entries persisted by both caches are kept
+	failingGetter := func(ctx context.Context, execPath string) (*version.Version, error) {
+		return nil, fmt.Errorf("unexpected call for %s", execPath)
+	}
+	vc := NewVersionCache(cachePath)
+	for i, binPath := range binPaths {
+		v, err := vc.getter("tool", failingGetter)(ctx, binPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := fmt.Sprintf("1.%d.0", i); v.String() != expected {
+			t.Fatalf("expected version %s, got %s", expected, v)
+		}
+	}
+}
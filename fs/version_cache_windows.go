@@ -0,0 +1,14 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package fs
+
+import (
+	"os"
+)
+
+// fileInode is not available on Windows without opening the file,
+// so only size and modification time are used to detect changes
+func fileInode(fi os.FileInfo) uint64 {
+	return 0
+}
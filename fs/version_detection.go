@@ -0,0 +1,92 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package fs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/chushi-io/lf-install/product"
+	"github.com/hashicorp/go-version"
+)
+
+// VersionDetection determines how versions of found binaries are obtained
+type VersionDetection int
+
+const (
+	// DetectByExecution executes the binary via Product.GetVersion
+	DetectByExecution VersionDetection = iota
+
+	// DetectByBuildInfo reads the version from Go build information
+	// embedded in the binary (see product.ReadBuildInfoVersion) and
+	// falls back to executing it in a scrubbed environment, which
+	// requires the product to set HonorsExecEnv
+	DetectByBuildInfo
+
+	// DetectByBuildInfoOnly reads the version from Go build information
+	// and never executes the binary
+	DetectByBuildInfoOnly
+)
+
+// scrubbedEnvVars represents variables passed through
+// when executing binaries to obtain their version
+var scrubbedEnvVars = []string{
+	// required by Go binaries to initialize on Windows
+	"SYSTEMROOT",
+}
+
+// VersionGetter returns a function obtaining version of the product's
+// binary at the given path per the detection mode
+func VersionGetter(p product.Product, mode VersionDetection) func(ctx context.Context, execPath string) (*version.Version, error) {
+	return versionGetterFor(p, mode)
+}
+
+// versionGetterFor returns a function obtaining version
+// of the product's binary per the given detection mode
+func versionGetterFor(p product.Product, mode VersionDetection) versionGetter {
+	if mode == DetectByExecution {
+		return p.GetVersion
+	}
+
+	bi := product.BuildInfo{}
+	if p.BuildInfo != nil {
+		bi = *p.BuildInfo
+	}
+
+	return func(ctx context.Context, execPath string) (*version.Version, error) {
+		vi, err := product.ReadBuildInfoVersion(execPath, bi)
+		if err == nil {
+			return vi.Version, nil
+		}
+		if mode == DetectByBuildInfoOnly || p.GetVersion == nil {
+			return nil, fmt.Errorf("unable to read version from build info: %w", err)
+		}
+		if !p.HonorsExecEnv {
+			return nil, fmt.Errorf("unable to read version from build info: %w "+
+				"(%s can't be executed in a scrubbed environment as it doesn't set HonorsExecEnv)",
+				err, p.Name)
+		}
+
+		return p.GetVersion(product.WithExecEnv(ctx, scrubbedEnv()), execPath)
+	}
+}
+
+// scrubbedEnv returns a minimal environment which doesn't leak
+// credentials or configuration to executed binaries
+func scrubbedEnv() []string {
+	env := []string{
+		// avoid any update checks
+		"CHECKPOINT_DISABLE=1",
+	}
+	if runtime.GOOS == "windows" {
+		for _, key := range scrubbedEnvVars {
+			if v, ok := os.LookupEnv(key); ok {
+				env = append(env, key+"="+v)
+			}
+		}
+	}
+	return env
+}
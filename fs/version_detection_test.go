@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/chushi-io/lf-install/product"
+	"github.com/hashicorp/go-version"
+)
+
+func TestVersionGetterFor_notGoBinary(t *testing.T) {
+	binPath := filepath.Join(t.TempDir(), "tool")
+	err := os.WriteFile(binPath, []byte("#!/bin/sh\necho 1.0.0"), 0o700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	executed := false
+	p := product.Product{
+		Name: "tool",
+		GetVersion: func(ctx context.Context, execPath string) (*version.Version, error) {
+			executed = true
+			return version.NewVersion("1.0.0")
+		},
+	}
+
+	_, err = versionGetterFor(p, DetectByBuildInfoOnly)(context.Background(), binPath)
+	if err == nil {
+		t.Fatal("expected error for binary without build info")
+	}
+	if executed {
+		t.Fatal("expected binary not to be executed")
+	}
+
+	// binaries are only executed if the product honors the scrubbed environment
+	_, err = versionGetterFor(p, DetectByBuildInfo)(context.Background(), binPath)
+	if err == nil {
+		t.Fatal("expected error for product which doesn't honor the scrubbed environment")
+	}
+	if executed {
+		t.Fatal("expected binary not to be executed")
+	}
+
+	p.HonorsExecEnv = true
+	v, err := versionGetterFor(p, DetectByBuildInfo)(context.Background(), binPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !executed || v.String() != "1.0.0" {
+		t.Fatalf("expected fallback to execution, got %s", v)
+	}
+}
+
+func TestVersionGetterFor_scrubbedEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell script")
+	}
+	t.Setenv("LF_INSTALL_TEST_SECRET", "secret")
+
+	binPath := filepath.Join(t.TempDir(), "tool")
+	err := os.WriteFile(binPath, []byte("#!/bin/sh\necho \"1.0.0-${LF_INSTALL_TEST_SECRET:-scrubbed}\""), 0o700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defs, err := product.ParseDefinitions(strings.NewReader(`{"products": [{
+  "name": "tool",
+  "version": {"args": [], "regex": "^(?P<version>.+)$"}
+}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := defs[0].Product()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := versionGetterFor(p, DetectByBuildInfo)(context.Background(), binPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.String() != "1.0.0-scrubbed" {
+		t.Fatalf("expected binary to be executed in a scrubbed environment, got %s", v)
+	}
+
+	v, err = versionGetterFor(p, DetectByExecution)(context.Background(), binPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.String() != "1.0.0-secret" {
+		t.Fatalf("expected binary to be executed in the current environment, got %s", v)
+	}
+}
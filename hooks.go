@@ -0,0 +1,206 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package install
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/chushi-io/lf-install/src"
+	"github.com/hashicorp/go-version"
+)
+
+// Hook represents a hook to be called at defined points of the installation
+// lifecycle. It is expected to implement one or more of PreResolveHook,
+// PostDownloadHook or PostInstallHook.
+//
+// Any error returned from a hook aborts the installation and anything
+// installed by the source so far is removed.
+type Hook interface{}
+
+// PreResolveHook is called before each source attempts to find,
+// install or build the product (i.e. before the version is resolved)
+type PreResolveHook interface {
+	PreResolve(ctx context.Context, event HookEvent) error
+}
+
+// PostDownloadHook is called once a source downloads
+// and verifies an archive, prior to unpacking it
+type PostDownloadHook interface {
+	PostDownload(ctx context.Context, event HookEvent) error
+}
+
+// PostInstallHook is called once a source finds,
+// installs or builds the product
+type PostInstallHook interface {
+	PostInstall(ctx context.Context, event HookEvent) error
+}
+
+// HookEvent describes the lifecycle point a hook is called at
+type HookEvent struct {
+	// Product represents name of the product (empty if unknown)
+	Product string
+
+	// Version represents the resolved version
+	// (nil prior to resolution or if unknown)
+	Version *version.Version
+
+	// Source represents the source being used
+	Source src.Source
+
+	// ExecPath represents path to the executable (post-install only)
+	ExecPath string
+
+	// Paths represents paths to relevant files, i.e. the downloaded archive
+	// (post-download) or all files created by the source (post-install)
+	Paths []string
+}
+
+// HookError is returned when a hook fails
+type HookError struct {
+	// Stage represents the lifecycle point, e.g. "post-install"
+	Stage string
+	Err   error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("%s hook failed: %s", e.Stage, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// AddHook adds a hook to be called at lifecycle points
+// corresponding to interfaces it implements
+func (i *Installer) AddHook(hook Hook) {
+	i.hooks = append(i.hooks, hook)
+}
+
+// runSource runs f per fallback policy, surrounded by any hooks.
+// Anything installed by the source is removed if a hook fails,
+// and any files it replaced are restored.
+func (i *Installer) runSource(ctx context.Context, source src.Source, f func(context.Context) (string, error)) (string, bool, error) {
+	err := i.runPreResolveHooks(ctx, source)
+	if err != nil {
+		return "", false, err
+	}
+
+	i.setPostDownloadHooks(source)
+
+	// replaced files are only discarded once the installation succeeds
+	cs, committable := source.(src.Committable)
+	if committable {
+		cs.SetKeepBackups(true)
+	}
+
+	execPath, skip, err := i.fallbackPolicy.run(ctx, i, f)
+	if err != nil {
+		var hookErr *HookError
+		if stderrors.As(err, &hookErr) {
+			i.removeSource(ctx, source)
+		}
+		return "", skip, err
+	}
+
+	err = i.runPostInstallHooks(ctx, source, execPath)
+	if err != nil {
+		i.removeSource(ctx, source)
+		return "", false, err
+	}
+
+	if committable {
+		cs.Commit()
+	}
+
+	return execPath, false, nil
+}
+
+func (i *Installer) removeSource(ctx context.Context, source src.Source) {
+	rs, ok := source.(src.Removable)
+	if !ok {
+		return
+	}
+	if err := rs.Remove(ctx); err != nil {
+		i.logger.Printf("failed to remove installation after hook failure: %s", err)
+	}
+}
+
+func (i *Installer) runPreResolveHooks(ctx context.Context, source src.Source) error {
+	// details of any previous call of the source
+	// (e.g. version and files) don't apply yet
+	event := HookEvent{Source: source}
+	if ds, ok := source.(src.Describable); ok {
+		event.Product = ds.Details().Product
+	}
+	for _, hook := range i.hooks {
+		if h, ok := hook.(PreResolveHook); ok {
+			if err := h.PreResolve(ctx, event); err != nil {
+				return &HookError{Stage: "pre-resolve", Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+func (i *Installer) setPostDownloadHooks(source src.Source) {
+	s, ok := source.(src.PostDownloadHookSettable)
+	if !ok {
+		return
+	}
+
+	hooks := make([]PostDownloadHook, 0)
+	for _, hook := range i.hooks {
+		if h, ok := hook.(PostDownloadHook); ok {
+			hooks = append(hooks, h)
+		}
+	}
+	if len(hooks) == 0 {
+		// clear any hook set by another Installer using the same source
+		s.SetPostDownloadHook(nil)
+		return
+	}
+
+	s.SetPostDownloadHook(func(ctx context.Context, details src.Details, archivePath string) error {
+		event := HookEvent{
+			Product: details.Product,
+			Version: details.Version,
+			Source:  source,
+			Paths:   []string{archivePath},
+		}
+		for _, h := range hooks {
+			if err := h.PostDownload(ctx, event); err != nil {
+				return &HookError{Stage: "post-download", Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+func (i *Installer) runPostInstallHooks(ctx context.Context, source src.Source, execPath string) error {
+	event := newHookEvent(source)
+	event.ExecPath = execPath
+	for _, hook := range i.hooks {
+		if h, ok := hook.(PostInstallHook); ok {
+			if err := h.PostInstall(ctx, event); err != nil {
+				return &HookError{Stage: "post-install", Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+func newHookEvent(source src.Source) HookEvent {
+	event := HookEvent{
+		Source: source,
+	}
+	if ds, ok := source.(src.Describable); ok {
+		details := ds.Details()
+		event.Product = details.Product
+		event.Version = details.Version
+		event.Paths = details.Files
+	}
+	return event
+}
@@ -0,0 +1,311 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package install_test
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	install "github.com/chushi-io/lf-install"
+	"github.com/chushi-io/lf-install/errors"
+	"github.com/chushi-io/lf-install/fs"
+	"github.com/chushi-io/lf-install/internal/testutil"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/chushi-io/lf-install/sbom"
+	"github.com/chushi-io/lf-install/src"
+)
+
+func TestInstaller_Ensure_hooks(t *testing.T) {
+	dir := t.TempDir()
+
+	i := install.NewInstaller()
+	i.SetLogger(testutil.TestLogger())
+
+	hook := &recordingHook{}
+	i.AddHook(hook)
+
+	execPath, err := i.Ensure(context.Background(), []src.Source{
+		&removableInstallable{fakeInstallable{dir: dir, files: []string{"tofu", "LICENSE"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedCalls := []string{"pre-resolve", "post-install"}
+	if fmt.Sprint(hook.calls) != fmt.Sprint(expectedCalls) {
+		t.Fatalf("expected calls %q, got %q", expectedCalls, hook.calls)
+	}
+	if hook.lastEvent.ExecPath != execPath {
+		t.Fatalf("unexpected exec path: %q", hook.lastEvent.ExecPath)
+	}
+	if hook.lastEvent.Version.String() != "1.8.0" {
+		t.Fatalf("unexpected version: %s", hook.lastEvent.Version)
+	}
+	if len(hook.lastEvent.Paths) != 2 {
+		t.Fatalf("expected 2 paths, got %q", hook.lastEvent.Paths)
+	}
+}
+
+func TestInstaller_Ensure_endOfSupport(t *testing.T) {
+	dir := t.TempDir()
+
+	lc, err := product.ParseLifecycle(strings.NewReader(
+		`{"cycles": [{"cycle": "1.8", "end_of_support": "2025-01-01"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i := install.NewInstaller()
+	i.SetLogger(testutil.TestLogger())
+	// the fake source doesn't report product name
+	i.AddHook(&install.EndOfSupportCheck{
+		Product: product.Product{Lifecycle: lc},
+		Action:  product.EndOfSupportFail,
+	})
+
+	_, err = i.Ensure(context.Background(), []src.Source{
+		&removableInstallable{fakeInstallable{dir: dir, files: []string{"tofu"}}},
+	})
+	if !stderrors.Is(err, errors.ErrEndOfSupport) {
+		t.Fatalf("expected end of support error, got %v", err)
+	}
+	assertExists(t, filepath.Join(dir, "tofu"), false)
+}
+
+func TestInstaller_Ensure_sbom(t *testing.T) {
+	dir := t.TempDir()
+	sbomPath := filepath.Join(t.TempDir(), "tofu.cdx.json")
+
+	i := install.NewInstaller()
+	i.SetLogger(testutil.TestLogger())
+	i.AddHook(&install.SBOMWriter{Format: sbom.CycloneDX, Path: sbomPath})
+
+	_, err := i.Ensure(context.Background(), []src.Source{
+		&removableInstallable{fakeInstallable{dir: dir, files: []string{"tofu", "LICENSE"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(sbomPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`"specVersion": "1.5"`, `"version": "1.8.0"`, `"name": "LICENSE"`} {
+		if !strings.Contains(string(b), expected) {
+			t.Fatalf("expected %s in SBOM:\n%s", expected, b)
+		}
+	}
+}
+
+func TestInstaller_Ensure_sbomDefaultPath(t *testing.T) {
+	dir := t.TempDir()
+
+	i := install.NewInstaller()
+	i.SetLogger(testutil.TestLogger())
+	i.AddHook(&install.SBOMWriter{Format: sbom.SPDX})
+
+	execPath, err := i.Ensure(context.Background(), []src.Source{
+		&removableInstallable{fakeInstallable{dir: dir, files: []string{"tofu"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertExists(t, execPath+".spdx.json", true)
+
+	// binaries not installed by the source are left alone
+	binPath := filepath.Join(t.TempDir(), "tofu")
+	err = os.WriteFile(binPath, []byte("binary"), 0o700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = i.Ensure(context.Background(), []src.Source{
+		&fs.AnyVersion{ExactBinPath: binPath},
+	})
+	var he *install.HookError
+	if !stderrors.As(err, &he) {
+		t.Fatalf("expected hook error, got %v", err)
+	}
+	assertExists(t, binPath+".spdx.json", false)
+}
+
+func TestInstaller_Ensure_hookFailure(t *testing.T) {
+	dir := t.TempDir()
+
+	i := install.NewInstaller()
+	i.SetLogger(testutil.TestLogger())
+
+	hookErr := fmt.Errorf("policy violation")
+	i.AddHook(&recordingHook{postInstallErr: hookErr})
+
+	fallback := &fakeFailingInstallable{}
+	_, err := i.Ensure(context.Background(), []src.Source{
+		&removableInstallable{fakeInstallable{dir: dir, files: []string{"tofu"}}},
+		fallback,
+	})
+	if err == nil {
+		t.Fatal("expected hook error")
+	}
+
+	var he *install.HookError
+	if !stderrors.As(err, &he) || he.Stage != "post-install" {
+		t.Fatalf("expected post-install hook error, got %q", err)
+	}
+	if !stderrors.Is(err, hookErr) {
+		t.Fatalf("expected original hook error to be wrapped, got %q", err)
+	}
+	if fallback.attempts != 0 {
+		t.Fatal("expected fallback source not to be used")
+	}
+	assertExists(t, filepath.Join(dir, "tofu"), false)
+}
+
+type recordingHook struct {
+	postInstallErr error
+
+	calls           []string
+	lastEvent       install.HookEvent
+	preResolveEvent install.HookEvent
+}
+
+func (h *recordingHook) PreResolve(ctx context.Context, event install.HookEvent) error {
+	h.calls = append(h.calls, "pre-resolve")
+	h.lastEvent = event
+	h.preResolveEvent = event
+	return nil
+}
+
+func (h *recordingHook) PostDownload(ctx context.Context, event install.HookEvent) error {
+	h.calls = append(h.calls, "post-download")
+	h.lastEvent = event
+	return nil
+}
+
+func (h *recordingHook) PostInstall(ctx context.Context, event install.HookEvent) error {
+	h.calls = append(h.calls, "post-install")
+	h.lastEvent = event
+	return h.postInstallErr
+}
+
+type removableInstallable struct {
+	fakeInstallable
+}
+
+func (ri *removableInstallable) Remove(ctx context.Context) error {
+	for _, path := range ri.details.Files {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestInstaller_Ensure_commit(t *testing.T) {
+	testCases := map[string]struct {
+		hookErr           error
+		expectedCommitted bool
+		expectedRestored  bool
+	}{
+		"success":      {expectedCommitted: true},
+		"hook-failure": {hookErr: fmt.Errorf("policy violation"), expectedRestored: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			i := install.NewInstaller()
+			i.SetLogger(testutil.TestLogger())
+			i.AddHook(&recordingHook{postInstallErr: tc.hookErr})
+
+			source := &committableInstallable{
+				removableInstallable: removableInstallable{fakeInstallable{dir: t.TempDir(), files: []string{"tofu"}}},
+			}
+			_, err := i.Ensure(context.Background(), []src.Source{source})
+			if (err != nil) != (tc.hookErr != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !source.keepBackups {
+				t.Fatal("expected source to be asked to keep backups")
+			}
+			if source.committed != tc.expectedCommitted {
+				t.Fatalf("expected committed: %t, given %t", tc.expectedCommitted, source.committed)
+			}
+			if source.restored != tc.expectedRestored {
+				t.Fatalf("expected restored: %t, given %t", tc.expectedRestored, source.restored)
+			}
+		})
+	}
+}
+
+// committableInstallable records how backups of replaced files are handled
+type committableInstallable struct {
+	removableInstallable
+
+	keepBackups bool
+	committed   bool
+	restored    bool
+}
+
+func (ci *committableInstallable) SetKeepBackups(keep bool) {
+	ci.keepBackups = keep
+}
+
+func (ci *committableInstallable) Commit() {
+	ci.committed = true
+}
+
+func (ci *committableInstallable) Remove(ctx context.Context) error {
+	if !ci.committed {
+		ci.restored = true
+	}
+	return ci.removableInstallable.Remove(ctx)
+}
+
+func TestInstaller_Ensure_reusedSource(t *testing.T) {
+	source := &downloadingInstallable{
+		removableInstallable: removableInstallable{fakeInstallable{dir: t.TempDir(), files: []string{"tofu"}}},
+	}
+
+	hook := &recordingHook{}
+	i := install.NewInstaller()
+	i.SetLogger(testutil.TestLogger())
+	i.AddHook(hook)
+
+	for n := 0; n < 2; n++ {
+		if _, err := i.Ensure(context.Background(), []src.Source{source}); err != nil {
+			t.Fatal(err)
+		}
+		// details of the previous installation are not reported
+		if hook.preResolveEvent.Version != nil || len(hook.preResolveEvent.Paths) != 0 {
+			t.Fatalf("unexpected pre-resolve event: %#v", hook.preResolveEvent)
+		}
+	}
+	if source.hook == nil {
+		t.Fatal("expected post-download hook to be set")
+	}
+
+	// another Installer without hooks clears the post-download hook
+	if _, err := install.NewInstaller().Ensure(context.Background(), []src.Source{source}); err != nil {
+		t.Fatal(err)
+	}
+	if source.hook != nil {
+		t.Fatal("expected post-download hook to be cleared")
+	}
+}
+
+// downloadingInstallable accepts post-download hooks
+type downloadingInstallable struct {
+	removableInstallable
+
+	hook src.PostDownloadHookFunc
+}
+
+func (di *downloadingInstallable) SetPostDownloadHook(hook src.PostDownloadHookFunc) {
+	di.hook = hook
+}
@@ -0,0 +1,138 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package install
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/chushi-io/lf-install/src"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-version"
+)
+
+// Installation represents a product version found, installed,
+// or built by the Installer
+type Installation struct {
+	// ExecPath represents path to the executable
+	ExecPath string
+
+	// Version represents the resolved version (nil if unknown)
+	Version *version.Version
+
+	// Source represents the source which provided the executable
+	Source src.Source
+
+	// Files represents all files and directories created
+	// by the installation (empty if the executable was found)
+	Files []string
+
+	// Advisories represents known vulnerabilities affecting
+	// the version, if the source checked any
+	Advisories []src.Advisory
+
+	mu      sync.Mutex
+	removed bool
+}
+
+func newInstallation(execPath string, source src.Source) *Installation {
+	in := &Installation{
+		ExecPath: execPath,
+		Source:   source,
+		Files:    make([]string, 0),
+	}
+
+	if ds, ok := source.(src.Describable); ok {
+		details := ds.Details()
+		in.Version = details.Version
+		in.Advisories = details.Advisories
+
+		seen := make(map[string]bool, 0)
+		for _, path := range details.Files {
+			path = cleanPath(path)
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			in.Files = append(in.Files, path)
+		}
+	}
+
+	installedFiles.acquire(in.Files)
+
+	return in
+}
+
+// Remove removes all files created by the installation.
+//
+// Files shared with other installations (e.g. when installing
+// into the same directory) are only removed once all
+// the installations sharing them are removed.
+func (in *Installation) Remove(ctx context.Context) error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if in.removed {
+		return nil
+	}
+	in.removed = true
+
+	var errs *multierror.Error
+	for _, path := range installedFiles.release(in.Files) {
+		err := os.RemoveAll(path)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// installedFiles keeps track of how many installations
+// created (or overwrote) each file
+var installedFiles = &fileRefs{
+	counts: make(map[string]int, 0),
+}
+
+type fileRefs struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (fr *fileRefs) acquire(paths []string) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	for _, path := range paths {
+		fr.counts[path]++
+	}
+}
+
+// release drops references to the given paths
+// and returns those which are no longer referenced,
+// in reverse order (i.e. files before their parent directories)
+func (fr *fileRefs) release(paths []string) []string {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	unreferenced := make([]string, 0)
+	for i := len(paths) - 1; i >= 0; i-- {
+		path := paths[i]
+		fr.counts[path]--
+		if fr.counts[path] <= 0 {
+			delete(fr.counts, path)
+			unreferenced = append(unreferenced, path)
+		}
+	}
+	return unreferenced
+}
+
+func cleanPath(path string) string {
+	if absPath, err := filepath.Abs(path); err == nil {
+		return absPath
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,107 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package install_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	install "github.com/chushi-io/lf-install"
+	isrc "github.com/chushi-io/lf-install/internal/src"
+	"github.com/chushi-io/lf-install/internal/testutil"
+	"github.com/chushi-io/lf-install/src"
+	"github.com/hashicorp/go-version"
+)
+
+func TestInstaller_EnsureInstallation_sharedDir(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	i := install.NewInstaller()
+	i.SetLogger(testutil.TestLogger())
+
+	first, err := i.EnsureInstallation(ctx, []src.Source{
+		&fakeInstallable{dir: dir, files: []string{"tofu", "LICENSE"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Version.String() != "1.8.0" {
+		t.Fatalf("unexpected version: %s", first.Version)
+	}
+	if len(first.Files) != 2 {
+		t.Fatalf("expected 2 files, got %q", first.Files)
+	}
+
+	second, err := i.EnsureInstallation(ctx, []src.Source{
+		&fakeInstallable{dir: dir, files: []string{"tofu", "README.md"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = first.Remove(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertExists(t, filepath.Join(dir, "tofu"), true)
+	assertExists(t, filepath.Join(dir, "LICENSE"), false)
+	assertExists(t, filepath.Join(dir, "README.md"), true)
+
+	// removing the same installation again is no-op
+	err = first.Remove(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertExists(t, filepath.Join(dir, "tofu"), true)
+
+	err = second.Remove(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertExists(t, filepath.Join(dir, "tofu"), false)
+	assertExists(t, filepath.Join(dir, "README.md"), false)
+}
+
+func assertExists(t *testing.T, path string, expected bool) {
+	t.Helper()
+	_, err := os.Stat(path)
+	if expected && err != nil {
+		t.Fatalf("expected %s to exist: %s", path, err)
+	}
+	if !expected && !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed: %v", path, err)
+	}
+}
+
+type fakeInstallable struct {
+	dir   string
+	files []string
+
+	details src.Details
+}
+
+func (*fakeInstallable) IsSourceImpl() isrc.InstallSrcSigil {
+	return isrc.InstallSrcSigil{}
+}
+
+func (fi *fakeInstallable) Install(ctx context.Context) (string, error) {
+	fi.details = src.Details{
+		Version: version.Must(version.NewVersion("1.8.0")),
+	}
+	for _, name := range fi.files {
+		path := filepath.Join(fi.dir, name)
+		if err := os.WriteFile(path, []byte(name), 0o700); err != nil {
+			return "", err
+		}
+		fi.details.Files = append(fi.details.Files, path)
+	}
+	return filepath.Join(fi.dir, fi.files[0]), nil
+}
+
+func (fi *fakeInstallable) Details() src.Details {
+	return fi.details
+}
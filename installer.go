@@ -9,13 +9,14 @@ import (
 	"io"
 	"log"
 
-	"github.com/chushi-io/lf-install/errors"
 	"github.com/chushi-io/lf-install/src"
 	"github.com/hashicorp/go-multierror"
 )
 
 type Installer struct {
-	logger *log.Logger
+	logger         *log.Logger
+	fallbackPolicy FallbackPolicy
+	hooks          []Hook
 
 	removableSources []src.Removable
 }
@@ -25,7 +26,8 @@ type RemoveFunc func(ctx context.Context) error
 func NewInstaller() *Installer {
 	discardLogger := log.New(io.Discard, "", 0)
 	return &Installer{
-		logger: discardLogger,
+		logger:         discardLogger,
+		fallbackPolicy: DefaultFallbackPolicy(),
 	}
 }
 
@@ -33,7 +35,30 @@ func (i *Installer) SetLogger(logger *log.Logger) {
 	i.logger = logger
 }
 
+// SetFallbackPolicy sets the policy which determines when to retry a source,
+// move on to the next one, or stop (see DefaultFallbackPolicy)
+func (i *Installer) SetFallbackPolicy(policy FallbackPolicy) {
+	i.fallbackPolicy = policy
+}
+
 func (i *Installer) Ensure(ctx context.Context, sources []src.Source) (string, error) {
+	execPath, _, err := i.ensure(ctx, sources)
+	return execPath, err
+}
+
+// EnsureInstallation finds, installs, or builds a product version
+// like Ensure, but returns a handle describing the installation,
+// which can be removed independently of any other installations.
+func (i *Installer) EnsureInstallation(ctx context.Context, sources []src.Source) (*Installation, error) {
+	execPath, source, err := i.ensure(ctx, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	return newInstallation(execPath, source), nil
+}
+
+func (i *Installer) ensure(ctx context.Context, sources []src.Source) (string, src.Source, error) {
 	var errs *multierror.Error
 
 	for _, source := range sources {
@@ -50,7 +75,7 @@ func (i *Installer) Ensure(ctx context.Context, sources []src.Source) (string, e
 	}
 
 	if errs.ErrorOrNil() != nil {
-		return "", errs
+		return "", nil, errs
 	}
 
 	i.removableSources = make([]src.Removable, 0)
@@ -60,46 +85,31 @@ func (i *Installer) Ensure(ctx context.Context, sources []src.Source) (string, e
 			i.removableSources = append(i.removableSources, s)
 		}
 
+		var f func(context.Context) (string, error)
 		switch s := source.(type) {
 		case src.Findable:
-			execPath, err := s.Find(ctx)
-			if err != nil {
-				if errors.IsErrorSkippable(err) {
-					errs = multierror.Append(errs, err)
-					continue
-				}
-				return "", err
-			}
-
-			return execPath, nil
+			f = s.Find
 		case src.Installable:
-			execPath, err := s.Install(ctx)
-			if err != nil {
-				if errors.IsErrorSkippable(err) {
-					errs = multierror.Append(errs, err)
-					continue
-				}
-				return "", err
-			}
-
-			return execPath, nil
+			f = s.Install
 		case src.Buildable:
-			execPath, err := s.Build(ctx)
-			if err != nil {
-				if errors.IsErrorSkippable(err) {
-					errs = multierror.Append(errs, err)
-					continue
-				}
-				return "", err
-			}
-
-			return execPath, nil
+			f = s.Build
 		default:
-			return "", fmt.Errorf("unknown source: %T", s)
+			return "", nil, fmt.Errorf("unknown source: %T", s)
+		}
+
+		execPath, skip, err := i.runSource(ctx, source, f)
+		if err != nil {
+			if skip {
+				errs = multierror.Append(errs, err)
+				continue
+			}
+			return "", nil, err
 		}
+
+		return execPath, source, nil
 	}
 
-	return "", fmt.Errorf("unable to find, install, or build from %d sources: %s",
+	return "", nil, fmt.Errorf("unable to find, install, or build from %d sources: %w",
 		len(sources), errs.ErrorOrNil())
 }
 
@@ -125,9 +135,9 @@ func (i *Installer) Install(ctx context.Context, sources []src.Installable) (str
 			i.removableSources = append(i.removableSources, s)
 		}
 
-		execPath, err := source.Install(ctx)
+		execPath, skip, err := i.runSource(ctx, source, source.Install)
 		if err != nil {
-			if errors.IsErrorSkippable(err) {
+			if skip {
 				errs = multierror.Append(errs, err)
 				continue
 			}
@@ -137,7 +147,7 @@ func (i *Installer) Install(ctx context.Context, sources []src.Installable) (str
 		return execPath, nil
 	}
 
-	return "", fmt.Errorf("unable install from %d sources: %s",
+	return "", fmt.Errorf("unable install from %d sources: %w",
 		len(sources), errs.ErrorOrNil())
 }
 
@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package lockfile provides exclusive advisory locks on files,
+// held by processes (and goroutines) sharing them
+package lockfile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// pollInterval represents how often a held lock is retried
+const pollInterval = 50 * time.Millisecond
+
+// Lock represents an exclusive lock held on a file
+type Lock struct {
+	f *os.File
+}
+
+// Acquire blocks until an exclusive lock on the file at path is acquired
+// or ctx is done. The file (along with its directory) is created
+// if it doesn't exist and is left in place when the lock is released.
+func Acquire(ctx context.Context, path string) (*Lock, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open lock file: %w", err)
+	}
+
+	for {
+		ok, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("unable to lock %q: %w", path, err)
+		}
+		if ok {
+			return &Lock{f: f}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, fmt.Errorf("waiting for lock on %q: %w", path, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Release releases the lock
+func (l *Lock) Release() error {
+	err := unlock(l.f)
+	if closeErr := l.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
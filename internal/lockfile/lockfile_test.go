@@ -0,0 +1,51 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lockfile
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "sub", "test.lock")
+	ctx := context.Background()
+
+	l, err := Acquire(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the lock is held per open file, so it's exclusive within the process too
+	waitCtx, cancelFunc := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancelFunc()
+	if _, err := Acquire(waitCtx, path); err == nil {
+		t.Fatal("expected held lock not to be acquired")
+	}
+
+	acquired := make(chan error)
+	go func() {
+		l, err := Acquire(ctx, path)
+		if err == nil {
+			err = l.Release()
+		}
+		acquired <- err
+	}()
+
+	if err := l.Release(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected released lock to be acquired")
+	}
+}
@@ -11,10 +11,10 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"net/url"
 	"strings"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/chushi-io/lf-install/errors"
 	"github.com/chushi-io/lf-install/internal/httpclient"
 )
 
@@ -53,10 +53,7 @@ func (cd *ChecksumDownloader) DownloadAndVerifyChecksums(ctx context.Context) (C
 	}
 
 	client := httpclient.NewHTTPClient(cd.Logger)
-	sigURL := fmt.Sprintf("%s/%s/%s/%s", cd.BaseURL,
-		url.PathEscape(cd.ProductVersion.Name),
-		url.PathEscape(cd.ProductVersion.Version.String()),
-		url.PathEscape(sigFilename))
+	sigURL := cd.ProductVersion.FileURL(cd.BaseURL, sigFilename)
 	cd.Logger.Printf("downloading signature from %s", sigURL)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sigURL, nil)
@@ -65,19 +62,20 @@ func (cd *ChecksumDownloader) DownloadAndVerifyChecksums(ctx context.Context) (C
 	}
 	sigResp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, &errors.NetworkError{URL: sigURL, Err: err}
 	}
 
 	if sigResp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to download signature from %q: %s", sigURL, sigResp.Status)
+		return nil, &errors.NetworkError{
+			URL:        sigURL,
+			StatusCode: sigResp.StatusCode,
+			Err:        fmt.Errorf("failed to download signature from %q: %s", sigURL, sigResp.Status),
+		}
 	}
 
 	defer sigResp.Body.Close()
 
-	shasumsURL := fmt.Sprintf("%s/%s/%s/%s", cd.BaseURL,
-		url.PathEscape(cd.ProductVersion.Name),
-		url.PathEscape(cd.ProductVersion.Version.String()),
-		url.PathEscape(cd.ProductVersion.SHASUMS))
+	shasumsURL := cd.ProductVersion.FileURL(cd.BaseURL, cd.ProductVersion.SHASUMS)
 	cd.Logger.Printf("downloading checksums from %s", shasumsURL)
 
 	req, err = http.NewRequestWithContext(ctx, http.MethodGet, shasumsURL, nil)
@@ -86,11 +84,15 @@ func (cd *ChecksumDownloader) DownloadAndVerifyChecksums(ctx context.Context) (C
 	}
 	sumsResp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, &errors.NetworkError{URL: shasumsURL, Err: err}
 	}
 
 	if sumsResp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to download checksums from %q: %s", shasumsURL, sumsResp.Status)
+		return nil, &errors.NetworkError{
+			URL:        shasumsURL,
+			StatusCode: sumsResp.StatusCode,
+			Err:        fmt.Errorf("failed to download checksums from %q: %s", shasumsURL, sumsResp.Status),
+		}
 	}
 
 	defer sumsResp.Body.Close()
@@ -143,7 +145,7 @@ func (cd *ChecksumDownloader) verifySumsSignature(checksums, signature io.Reader
 
 	_, err = openpgp.CheckDetachedSignature(el, checksums, signature, nil)
 	if err != nil {
-		return fmt.Errorf("unable to verify checksums signature: %w", err)
+		return &errors.SignatureError{Err: err}
 	}
 
 	cd.Logger.Printf("checksum signature is valid")
@@ -168,12 +170,13 @@ func (cd *ChecksumDownloader) findSigFilename(pv *ProductVersion) (string, error
 				return filename, nil
 			}
 		}
-		if strings.HasSuffix(filename, "_SHA256SUMS.sig") {
+		if strings.HasSuffix(filename, "_SHA256SUMS.sig") ||
+			strings.HasSuffix(filename, "_SHA256SUMS.gpgsig") {
 			return filename, nil
 		}
 	}
 
-	return "", fmt.Errorf("no suitable sig file found")
+	return "", &errors.SignatureError{Err: fmt.Errorf("no suitable sig file found")}
 }
 
 func (cd *ChecksumDownloader) pubKeyIds() ([]string, error) {
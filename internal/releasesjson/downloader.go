@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"log"
@@ -16,9 +17,11 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
-	"strings"
 
+	"github.com/chushi-io/lf-install/errors"
 	"github.com/chushi-io/lf-install/internal/httpclient"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/chushi-io/lf-install/src"
 )
 
 type Downloader struct {
@@ -26,21 +29,114 @@ type Downloader struct {
 	VerifyChecksum   bool
 	ArmoredPublicKey string
 	BaseURL          string
+
+	// ArchiveNameTemplate locates the archive by name rendered
+	// for the current platform (see product.Product.ArchiveNameTemplate)
+	// instead of by platform recorded in the index (optional)
+	ArchiveNameTemplate string
+
+	// AcceptOctetStream accepts archives served as application/octet-stream
+	// (e.g. assets of GitHub releases) in addition to ZIP media types
+	AcceptOctetStream bool
+
+	// MaxExtractedSize caps the total uncompressed size
+	// of the archive (1 GiB if unset)
+	MaxExtractedSize int64
+
+	// MaxExtractedFiles caps the number of archive entries (1000 if unset)
+	MaxExtractedFiles int
+
+	// SymlinkPolicy determines how to treat any symlinks in the archive
+	// (symlinks are rejected by default)
+	SymlinkPolicy SymlinkPolicy
+
+	// Rules determine which archive members to extract and where to.
+	// If nil, all members are extracted to binDir except for license
+	// files, which are extracted to licenseDir (if set).
+	Rules []ExtractRule
+
+	// VerifyArchive is called with name and SHA-256 checksum of the
+	// downloaded archive before it is unpacked (optional),
+	// e.g. to verify its provenance.
+	// Returning an error aborts the installation.
+	VerifyArchive func(ctx context.Context, filename string, sum HashSum) error
+
+	// PostDownload is called with path to the downloaded archive
+	// once verified and before it is unpacked (optional).
+	// Returning an error aborts the installation.
+	PostDownload func(ctx context.Context, archivePath string) error
+
+	// VerifyUnpacked is called with paths of all unpacked files, mapped
+	// from their destination to where they were staged, before they are
+	// moved into place (optional), e.g. to execute the unpacked binary.
+	// Returning an error aborts the installation, leaving any
+	// previously installed files untouched.
+	VerifyUnpacked func(ctx context.Context, stagedPaths map[string]string) error
+
+	// KeepBackups keeps backups of any files replaced by unpacking
+	// until either DiscardBackups or RestoreBackups of UnpackedProduct
+	// is called, e.g. until the installation as a whole succeeded
+	KeepBackups bool
 }
 
 type UnpackedProduct struct {
 	PathsToRemove []string
+
+	// ArchiveURL represents URL the archive was downloaded from
+	ArchiveURL string
+
+	// ArchiveFilename represents name of the archive
+	ArchiveFilename string
+
+	// ArchiveSHA256 represents checksum of the downloaded archive
+	// (calculated regardless of whether checksums are verified)
+	ArchiveSHA256 HashSum
+
+	// staging holds backups of replaced files if KeepBackups is set
+	staging *stagingArea
 }
 
-func (d *Downloader) DownloadAndUnpack(ctx context.Context, pv *ProductVersion, binDir string, licenseDir string) (up *UnpackedProduct, err error) {
-	if len(pv.Builds) == 0 {
-		return nil, fmt.Errorf("no builds found for %s %s", pv.Name, pv.Version)
+// DiscardBackups removes backups of files replaced by unpacking
+// (only kept if KeepBackups was set)
+func (up *UnpackedProduct) DiscardBackups() {
+	if up.staging == nil {
+		return
 	}
+	up.staging.discardBackups()
+	up.staging = nil
+}
 
-	pb, ok := pv.Builds.FilterBuild(runtime.GOOS, runtime.GOARCH, "zip")
+// RestoreBackups removes unpacked files and moves files replaced by them
+// back into place (only kept if KeepBackups was set)
+func (up *UnpackedProduct) RestoreBackups() {
+	if up.staging == nil {
+		return
+	}
+	up.staging.restoreBackups()
+	up.staging = nil
+}
+
+// Download describes the downloaded archive
+func (up *UnpackedProduct) Download() *src.Download {
+	return &src.Download{
+		URL:      up.ArchiveURL,
+		Filename: up.ArchiveFilename,
+		SHA256:   hex.EncodeToString(up.ArchiveSHA256),
+	}
+}
+
+func (d *Downloader) DownloadAndUnpack(ctx context.Context, pv *ProductVersion, binDir string, licenseDir string) (up *UnpackedProduct, err error) {
+	pb, ok, err := d.findBuild(pv)
+	if err != nil {
+		return nil, err
+	}
 	if !ok {
-		return nil, fmt.Errorf("no ZIP archive found for %s %s %s/%s",
-			pv.Name, pv.Version, runtime.GOOS, runtime.GOARCH)
+		return nil, &errors.NoBuildError{
+			Product: pv.Name,
+			Version: pv.Version.String(),
+			OS:      runtime.GOOS,
+			Arch:    runtime.GOARCH,
+		}
 	}
 
 	var verifiedChecksum HashSum
@@ -77,11 +173,15 @@ func (d *Downloader) DownloadAndUnpack(ctx context.Context, pv *ProductVersion,
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, &errors.NetworkError{URL: archiveURL, Err: err}
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to download ZIP archive from %q: %s", archiveURL, resp.Status)
+		return nil, &errors.NetworkError{
+			URL:        archiveURL,
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("failed to download ZIP archive from %q: %s", archiveURL, resp.Status),
+		}
 	}
 
 	defer resp.Body.Close()
@@ -89,7 +189,8 @@ func (d *Downloader) DownloadAndUnpack(ctx context.Context, pv *ProductVersion,
 	pkgReader := resp.Body
 
 	contentType := resp.Header.Get("content-type")
-	if !contentTypeIsZip(contentType) {
+	if !contentTypeIsZip(contentType) &&
+		!(d.AcceptOctetStream && contentType == "application/octet-stream") {
 		return nil, fmt.Errorf("unexpected content-type: %s (expected any of %q)",
 			contentType, zipMimeTypes)
 	}
@@ -108,38 +209,49 @@ func (d *Downloader) DownloadAndUnpack(ctx context.Context, pv *ProductVersion,
 
 	d.Logger.Printf("copying %q (%d bytes) to %s", pb.Filename, expectedSize, pkgFile.Name())
 
-	var bytesCopied int64
+	h := sha256.New()
+	bytesCopied, err := io.Copy(io.MultiWriter(pkgFile, h), pkgReader)
+	if err != nil {
+		return up, &errors.NetworkError{URL: archiveURL, Err: err}
+	}
+	calculatedSum := h.Sum(nil)
+
 	if d.VerifyChecksum {
 		d.Logger.Printf("verifying checksum of %q", pb.Filename)
-		h := sha256.New()
-		r := io.TeeReader(resp.Body, pkgFile)
-
-		bytesCopied, err = io.Copy(h, r)
-		if err != nil {
-			return nil, err
+		if !bytes.Equal(calculatedSum, verifiedChecksum) {
+			return up, &errors.ChecksumMismatchError{
+				Filename: pb.Filename,
+				Expected: verifiedChecksum,
+				Actual:   calculatedSum,
+			}
 		}
+	}
+	up.ArchiveURL = archiveURL
+	up.ArchiveFilename = pb.Filename
+	up.ArchiveSHA256 = calculatedSum
 
-		calculatedSum := h.Sum(nil)
-		if !bytes.Equal(calculatedSum, verifiedChecksum) {
-			return up, fmt.Errorf(
-				"checksum mismatch (expected: %x, got: %x)",
-				verifiedChecksum, calculatedSum,
-			)
+	d.Logger.Printf("copied %d bytes to %s", bytesCopied, pkgFile.Name())
+
+	if expectedSize != 0 && bytesCopied != int64(expectedSize) {
+		return up, &errors.SizeMismatchError{
+			Filename: pb.Filename,
+			Expected: expectedSize,
+			Actual:   bytesCopied,
 		}
-	} else {
-		bytesCopied, err = io.Copy(pkgFile, pkgReader)
+	}
+
+	if d.VerifyArchive != nil {
+		err = d.VerifyArchive(ctx, pb.Filename, calculatedSum)
 		if err != nil {
 			return up, err
 		}
 	}
 
-	d.Logger.Printf("copied %d bytes to %s", bytesCopied, pkgFile.Name())
-
-	if expectedSize != 0 && bytesCopied != int64(expectedSize) {
-		return up, fmt.Errorf(
-			"unexpected size (downloaded: %d, expected: %d)",
-			bytesCopied, expectedSize,
-		)
+	if d.PostDownload != nil {
+		err = d.PostDownload(ctx, pkgFilePath)
+		if err != nil {
+			return up, err
+		}
 	}
 
 	r, err := zip.OpenReader(pkgFile.Name())
@@ -148,47 +260,79 @@ func (d *Downloader) DownloadAndUnpack(ctx context.Context, pv *ProductVersion,
 	}
 	defer r.Close()
 
-	for _, f := range r.File {
-		if strings.Contains(f.Name, "..") {
-			// While we generally trust the source ZIP file
-			// we still reject path traversal attempts as a precaution.
-			continue
-		}
-		srcFile, err := f.Open()
-		if err != nil {
-			return up, err
+	// Files are unpacked into a staging area first and only moved
+	// into place once all of them were unpacked successfully,
+	// such that interrupted installation never leaves behind
+	// a truncated binary or clobbers a previously installed one.
+	staging := newStagingArea(d.Logger)
+	staging.keepBackups = d.KeepBackups
+	defer func() {
+		if err != nil || !d.KeepBackups {
+			staging.cleanup()
 		}
+	}()
 
-		// Determine the appropriate destination file path
-		dstDir := binDir
-		// for license files, use binDir if licenseDir is not set
-		if isLicenseFile(f.Name) && licenseDir != "" {
-			dstDir = licenseDir
+	e := d.newExtractor(staging)
+	err = e.extract(ctx, r.File, func(name string) (string, bool) {
+		if d.Rules != nil {
+			return matchRule(d.Rules, name)
 		}
-
-		d.Logger.Printf("unpacking %s to %s", f.Name, dstDir)
-		dstPath := filepath.Join(dstDir, f.Name)
-
-		if isLicenseFile(f.Name) {
-			up.PathsToRemove = append(up.PathsToRemove, dstPath)
+		// for license files, use binDir if licenseDir is not set
+		if isLicenseFile(name) && licenseDir != "" {
+			return licenseDir, true
 		}
+		return binDir, true
+	})
+	if err != nil {
+		return up, err
+	}
 
-		dstFile, err := os.Create(dstPath)
+	if d.VerifyUnpacked != nil {
+		err = d.VerifyUnpacked(ctx, staging.stagedPaths())
 		if err != nil {
 			return up, err
 		}
+	}
 
-		_, err = io.Copy(dstFile, srcFile)
-		if err != nil {
-			return up, err
-		}
-		srcFile.Close()
-		dstFile.Close()
+	committedPaths, err := staging.commit()
+	if err != nil {
+		return up, err
+	}
+
+	up.PathsToRemove = append(up.PathsToRemove, committedPaths...)
+	if d.KeepBackups {
+		up.staging = staging
 	}
 
 	return up, nil
 }
 
+// findBuild finds the build of the archive for the current platform
+func (d *Downloader) findBuild(pv *ProductVersion) (*ProductBuild, bool, error) {
+	if d.ArchiveNameTemplate == "" {
+		pb, ok := pv.Builds.FilterBuild(runtime.GOOS, runtime.GOARCH, "zip")
+		return pb, ok, nil
+	}
+
+	filename, err := product.RenderArchiveName(d.ArchiveNameTemplate, product.ArchiveName{
+		Name:    pv.Name,
+		Version: pv.Version.String(),
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	d.Logger.Printf("looking for archive %q", filename)
+
+	for _, pb := range pv.Builds {
+		if pb.Filename == filename {
+			return pb, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
 // The production release site uses consistent single mime type
 // but mime types are platform-dependent
 // and we may use different OS under test
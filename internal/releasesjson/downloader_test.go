@@ -3,7 +3,23 @@
 
 package releasesjson
 
-import "testing"
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/chushi-io/lf-install/internal/testutil"
+	"github.com/hashicorp/go-version"
+)
 
 func TestDetermineArchiveURL(t *testing.T) {
 	tests := []struct {
@@ -45,3 +61,151 @@ func TestDetermineArchiveURL(t *testing.T) {
 		})
 	}
 }
+
+// newArchiveTestServer serves a ZIP archive of tofu 1.6.2
+// for the current platform with the given content of the binary
+func newArchiveTestServer(t *testing.T, content string) (*ProductVersion, []byte) {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	fw, err := zw.Create("tofu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive := buf.Bytes()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Write(archive)
+	}))
+	t.Cleanup(ts.Close)
+
+	filename := "tofu_1.6.2_" + runtime.GOOS + "_" + runtime.GOARCH + ".zip"
+	pv := &ProductVersion{
+		Name:    "tofu",
+		Version: version.Must(version.NewVersion("1.6.2")),
+		Builds: ProductBuilds{{
+			Name:     "tofu",
+			Version:  "1.6.2",
+			OS:       runtime.GOOS,
+			Arch:     runtime.GOARCH,
+			Filename: filename,
+			URL:      ts.URL + "/tofu/1.6.2/" + filename,
+		}},
+	}
+	return pv, archive
+}
+
+func TestDownloader_DownloadAndUnpack_archive(t *testing.T) {
+	pv, archive := newArchiveTestServer(t, "binary")
+	filename := pv.Builds[0].Filename
+
+	d := &Downloader{Logger: testutil.TestLogger()}
+	dir := t.TempDir()
+	up, err := d.DownloadAndUnpack(context.Background(), pv, dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	download := up.Download()
+	if download.URL != pv.Builds[0].URL {
+		t.Fatalf("unexpected URL: %q", download.URL)
+	}
+	if download.Filename != filename {
+		t.Fatalf("unexpected filename: %q", download.Filename)
+	}
+	sum := sha256.Sum256(archive)
+	if !bytes.Equal(up.ArchiveSHA256, sum[:]) {
+		t.Fatalf("unexpected checksum: %x (expected %x)", up.ArchiveSHA256, sum)
+	}
+
+	found := false
+	for _, path := range up.PathsToRemove {
+		if path == filepath.Join(dir, "tofu") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected unpacked binary in %q", up.PathsToRemove)
+	}
+}
+
+func TestDownloader_DownloadAndUnpack_keepBackups(t *testing.T) {
+	pv, _ := newArchiveTestServer(t, "new")
+
+	for _, restore := range []bool{true, false} {
+		dir := t.TempDir()
+		execPath := filepath.Join(dir, "tofu")
+		if err := os.WriteFile(execPath, []byte("old"), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		d := &Downloader{Logger: testutil.TestLogger(), KeepBackups: true}
+		up, err := d.DownloadAndUnpack(context.Background(), pv, dir, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertContent(t, execPath, "new")
+
+		expected := "new"
+		if restore {
+			expected = "old"
+			up.RestoreBackups()
+		} else {
+			up.DiscardBackups()
+		}
+		assertContent(t, execPath, expected)
+		// the staging directory with backups is removed either way
+		assertDirEntries(t, dir, 1)
+	}
+}
+
+func assertContent(t *testing.T, path, expected string) {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != expected {
+		t.Fatalf("expected %q in %s, got %q", expected, path, b)
+	}
+}
+
+func TestDownloader_DownloadAndUnpack_verifyUnpacked(t *testing.T) {
+	pv, _ := newArchiveTestServer(t, "new")
+
+	dir := t.TempDir()
+	execPath := filepath.Join(dir, "tofu")
+	if err := os.WriteFile(execPath, []byte("old"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	verifyErr := fmt.Errorf("unexpected binary")
+	d := &Downloader{
+		Logger: testutil.TestLogger(),
+		VerifyUnpacked: func(ctx context.Context, stagedPaths map[string]string) error {
+			stagedPath, ok := stagedPaths[execPath]
+			if !ok {
+				t.Fatalf("expected %s to be staged, given %q", execPath, stagedPaths)
+			}
+			assertContent(t, stagedPath, "new")
+			return verifyErr
+		},
+	}
+	_, err := d.DownloadAndUnpack(context.Background(), pv, dir, "")
+	if !stderrors.Is(err, verifyErr) {
+		t.Fatalf("expected verification error, got %v", err)
+	}
+
+	// the previous binary is left in place
+	assertContent(t, execPath, "old")
+	assertDirEntries(t, dir, 1)
+}
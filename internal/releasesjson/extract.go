@@ -0,0 +1,221 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releasesjson
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SymlinkPolicy determines how symbolic links found in archives are treated
+type SymlinkPolicy int
+
+const (
+	// SymlinkReject fails the extraction if the archive contains any symlink
+	SymlinkReject SymlinkPolicy = iota
+	// SymlinkSkip ignores any symlinks found in the archive
+	SymlinkSkip
+	// SymlinkAllowInternal extracts symlinks as long as they are relative
+	// and point to a path within the same destination directory
+	SymlinkAllowInternal
+)
+
+const (
+	// defaultMaxExtractedSize caps the total uncompressed size
+	// of all files extracted from a single archive
+	defaultMaxExtractedSize int64 = 1 << 30 // 1 GiB
+
+	// defaultMaxExtractedFiles caps the number of entries
+	// extracted from a single archive
+	defaultMaxExtractedFiles = 1000
+)
+
+// extractor unpacks ZIP archives into a staging area
+// while guarding against path traversal and decompression bombs
+type extractor struct {
+	logger  *log.Logger
+	staging *stagingArea
+
+	maxTotalSize  int64
+	maxFiles      int
+	symlinkPolicy SymlinkPolicy
+
+	extractedSize int64
+}
+
+func (d *Downloader) newExtractor(staging *stagingArea) *extractor {
+	e := &extractor{
+		logger:        d.Logger,
+		staging:       staging,
+		maxTotalSize:  defaultMaxExtractedSize,
+		maxFiles:      defaultMaxExtractedFiles,
+		symlinkPolicy: d.SymlinkPolicy,
+	}
+	if d.MaxExtractedSize > 0 {
+		e.maxTotalSize = d.MaxExtractedSize
+	}
+	if d.MaxExtractedFiles > 0 {
+		e.maxFiles = d.MaxExtractedFiles
+	}
+	return e
+}
+
+// extract unpacks all given files into directories
+// determined by dstDirFunc for each member name,
+// skipping any members for which dstDirFunc returns false
+func (e *extractor) extract(ctx context.Context, files []*zip.File, dstDirFunc func(name string) (string, bool)) error {
+	if len(files) > e.maxFiles {
+		return fmt.Errorf("archive contains too many files (%d, limit: %d)",
+			len(files), e.maxFiles)
+	}
+
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		name, err := sanitizeMemberName(f.Name)
+		if err != nil {
+			return err
+		}
+
+		dstDir, ok := dstDirFunc(strings.TrimSuffix(f.Name, "/"))
+		if !ok {
+			e.logger.Printf("skipping %s", f.Name)
+			continue
+		}
+
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			e.logger.Printf("creating directory %s in %s", name, dstDir)
+			e.staging.stageDir(dstDir, name)
+		case mode&os.ModeSymlink != 0:
+			err = e.extractSymlink(f, dstDir, name)
+			if err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			e.logger.Printf("unpacking %s to %s", f.Name, dstDir)
+			err = e.extractFile(f, dstDir, name)
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported type of archive member %q: %s", f.Name, mode.Type())
+		}
+	}
+
+	return nil
+}
+
+func (e *extractor) extractFile(f *zip.File, dstDir, name string) error {
+	remaining := e.maxTotalSize - e.extractedSize
+	if f.UncompressedSize64 > uint64(remaining) {
+		return fmt.Errorf("archive exceeds size limit of %d bytes when unpacking %q",
+			e.maxTotalSize, f.Name)
+	}
+
+	stagedPath, err := e.staging.stagePath(dstDir, name)
+	if err != nil {
+		return err
+	}
+
+	srcFile, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.OpenFile(stagedPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileMode(f.Mode()))
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+
+	// read one more byte than allowed, so we can tell whether
+	// the member is larger than what its header claims
+	n, err := io.Copy(dstFile, io.LimitReader(srcFile, remaining+1))
+	if err != nil {
+		return err
+	}
+	e.extractedSize += n
+	if e.extractedSize > e.maxTotalSize {
+		return fmt.Errorf("archive exceeds size limit of %d bytes when unpacking %q",
+			e.maxTotalSize, f.Name)
+	}
+
+	return dstFile.Close()
+}
+
+func (e *extractor) extractSymlink(f *zip.File, dstDir, name string) error {
+	switch e.symlinkPolicy {
+	case SymlinkSkip:
+		e.logger.Printf("skipping symlink %s", f.Name)
+		return nil
+	case SymlinkAllowInternal:
+	default:
+		return fmt.Errorf("archive contains symlink %q", f.Name)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	b, err := io.ReadAll(io.LimitReader(rc, 4096))
+	if err != nil {
+		return err
+	}
+	target := string(b)
+
+	// the link target is resolved relative to the link itself
+	// and must stay within the destination directory
+	if path.IsAbs(target) || filepath.IsAbs(target) ||
+		!filepath.IsLocal(filepath.Join(filepath.Dir(name), filepath.FromSlash(target))) {
+		return fmt.Errorf("symlink %q points outside of destination: %q", f.Name, target)
+	}
+
+	stagedPath, err := e.staging.stagePath(dstDir, name)
+	if err != nil {
+		return err
+	}
+
+	e.logger.Printf("creating symlink %s -> %s in %s", name, target, dstDir)
+	return os.Symlink(filepath.FromSlash(target), stagedPath)
+}
+
+// sanitizeMemberName converts the name of an archive member
+// into a local OS-specific path, rejecting any names which
+// would be placed outside of the destination directory
+func sanitizeMemberName(name string) (string, error) {
+	if strings.Contains(name, `\`) {
+		return "", fmt.Errorf("invalid archive member name %q", name)
+	}
+
+	cleanName := strings.TrimSuffix(path.Clean(name), "/")
+	localName := filepath.FromSlash(cleanName)
+	if !filepath.IsLocal(localName) {
+		return "", fmt.Errorf("archive member %q points outside of destination", name)
+	}
+
+	return localName, nil
+}
+
+// fileMode returns permissions to use for an extracted file,
+// preserving any executable bits of the archived one
+func fileMode(mode os.FileMode) os.FileMode {
+	if mode.Perm()&0o111 != 0 {
+		return 0o755
+	}
+	return 0o644
+}
@@ -0,0 +1,189 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releasesjson
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/chushi-io/lf-install/internal/testutil"
+)
+
+type testArchiveMember struct {
+	name    string
+	mode    os.FileMode
+	content string
+}
+
+func TestExtractor_extract(t *testing.T) {
+	files := testArchive(t, []testArchiveMember{
+		{name: "tofu", mode: 0o755, content: "binary"},
+		{name: "LICENSE", mode: 0o644, content: "license"},
+		{name: "share/", mode: os.ModeDir | 0o755},
+		{name: "share/man/tofu.1", mode: 0o644, content: "man"},
+	})
+
+	dstDir := t.TempDir()
+	paths := extractTestArchive(t, &Downloader{}, files, dstDir)
+	if len(paths) != 5 {
+		t.Fatalf("expected 5 created paths, got %q", paths)
+	}
+
+	fi, err := os.Stat(filepath.Join(dstDir, "tofu"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && fi.Mode().Perm()&0o111 == 0 {
+		t.Fatalf("expected executable bits to be preserved, got %s", fi.Mode())
+	}
+
+	b, err := os.ReadFile(filepath.Join(dstDir, "share", "man", "tofu.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "man" {
+		t.Fatalf("unexpected content: %q", b)
+	}
+}
+
+func TestExtractor_extract_rejected(t *testing.T) {
+	testCases := map[string]struct {
+		d       *Downloader
+		members []testArchiveMember
+	}{
+		"path-traversal": {
+			d: &Downloader{},
+			members: []testArchiveMember{
+				{name: "../tofu", mode: 0o755, content: "binary"},
+			},
+		},
+		"absolute-path": {
+			d: &Downloader{},
+			members: []testArchiveMember{
+				{name: "/tmp/tofu", mode: 0o755, content: "binary"},
+			},
+		},
+		"size-limit": {
+			d: &Downloader{MaxExtractedSize: 10},
+			members: []testArchiveMember{
+				{name: "tofu", mode: 0o755, content: "more than ten bytes"},
+			},
+		},
+		"file-count-limit": {
+			d: &Downloader{MaxExtractedFiles: 1},
+			members: []testArchiveMember{
+				{name: "tofu", mode: 0o755, content: "binary"},
+				{name: "LICENSE", mode: 0o644, content: "license"},
+			},
+		},
+		"symlink": {
+			d: &Downloader{},
+			members: []testArchiveMember{
+				{name: "tofu", mode: os.ModeSymlink | 0o777, content: "bin/tofu"},
+			},
+		},
+		"symlink-outside": {
+			d: &Downloader{SymlinkPolicy: SymlinkAllowInternal},
+			members: []testArchiveMember{
+				{name: "tofu", mode: os.ModeSymlink | 0o777, content: "../../usr/bin/tofu"},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			files := testArchive(t, tc.members)
+			dstDir := t.TempDir()
+
+			staging := newStagingArea(testutil.TestLogger())
+			tc.d.Logger = testutil.TestLogger()
+			err := tc.d.newExtractor(staging).extract(context.Background(), files,
+				func(string) (string, bool) { return dstDir, true })
+			staging.cleanup()
+			if err == nil {
+				t.Fatal("expected extraction to fail")
+			}
+			t.Log(err)
+
+			assertDirEntries(t, dstDir, 0)
+		})
+	}
+}
+
+func TestExtractor_extract_symlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require elevated privileges on Windows")
+	}
+
+	files := testArchive(t, []testArchiveMember{
+		{name: "bin/tofu", mode: 0o755, content: "binary"},
+		{name: "tofu", mode: os.ModeSymlink | 0o777, content: "bin/tofu"},
+	})
+
+	dstDir := t.TempDir()
+	extractTestArchive(t, &Downloader{SymlinkPolicy: SymlinkAllowInternal}, files, dstDir)
+	target, err := os.Readlink(filepath.Join(dstDir, "tofu"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target != filepath.Join("bin", "tofu") {
+		t.Fatalf("unexpected symlink target: %q", target)
+	}
+
+	dstDir = t.TempDir()
+	extractTestArchive(t, &Downloader{SymlinkPolicy: SymlinkSkip}, files, dstDir)
+	if _, err := os.Lstat(filepath.Join(dstDir, "tofu")); !os.IsNotExist(err) {
+		t.Fatalf("expected symlink to be skipped: %v", err)
+	}
+}
+
+func extractTestArchive(t *testing.T, d *Downloader, files []*zip.File, dstDir string) []string {
+	t.Helper()
+
+	d.Logger = testutil.TestLogger()
+	staging := newStagingArea(d.Logger)
+	err := d.newExtractor(staging).extract(context.Background(), files,
+		func(string) (string, bool) { return dstDir, true })
+	if err != nil {
+		staging.cleanup()
+		t.Fatal(err)
+	}
+	paths, err := staging.commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paths
+}
+
+func testArchive(t *testing.T, members []testArchiveMember) []*zip.File {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for _, m := range members {
+		fh := &zip.FileHeader{Name: m.name, Method: zip.Deflate}
+		fh.SetMode(m.mode)
+		fw, err := w.CreateHeader(fh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(m.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r.File
+}
@@ -0,0 +1,194 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releasesjson
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chushi-io/lf-install/errors"
+	"github.com/chushi-io/lf-install/internal/httpclient"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/hashicorp/go-version"
+)
+
+const (
+	gitHubReleasesPerPage = 100
+
+	// gitHubMaxReleasesPages caps the number of pages of releases listed
+	gitHubMaxReleasesPages = 10
+)
+
+// gitHubRelease represents a release in the GitHub API
+type gitHubRelease struct {
+	TagName     string        `json:"tag_name"`
+	Draft       bool          `json:"draft"`
+	PublishedAt *time.Time    `json:"published_at"`
+	Assets      []gitHubAsset `json:"assets"`
+}
+
+type gitHubAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+func (r *Releases) isGitHub() bool {
+	return r.Backend == product.ReleasesBackendGitHub
+}
+
+// listGitHubReleases lists versions of the product released
+// in the repository at r.BaseURL, excluding drafts and releases
+// whose tags aren't versions
+func (r *Releases) listGitHubReleases(ctx context.Context, productName string) (ProductVersionsMap, error) {
+	pvs := make(ProductVersionsMap, 0)
+
+	for page := 1; page <= gitHubMaxReleasesPages; page++ {
+		releasesURL := fmt.Sprintf("%s/releases?per_page=%d&page=%d",
+			strings.TrimSuffix(r.BaseURL, "/"), gitHubReleasesPerPage, page)
+
+		var releases []gitHubRelease
+		_, err := r.getGitHubJSON(ctx, releasesURL, &releases)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rel := range releases {
+			pv, ok := rel.productVersion(productName)
+			if !ok {
+				continue
+			}
+			pvs[pv.Version.String()] = pv
+		}
+
+		if len(releases) < gitHubReleasesPerPage {
+			break
+		}
+	}
+
+	return pvs, nil
+}
+
+// getGitHubRelease obtains the release of the given version,
+// tagged either with or without the "v" prefix
+func (r *Releases) getGitHubRelease(ctx context.Context, productName string, v *version.Version) (*ProductVersion, error) {
+	var lastErr error
+	for _, tag := range []string{"v" + v.String(), v.String()} {
+		releaseURL := fmt.Sprintf("%s/releases/tags/%s",
+			strings.TrimSuffix(r.BaseURL, "/"), url.PathEscape(tag))
+
+		var rel gitHubRelease
+		statusCode, err := r.getGitHubJSON(ctx, releaseURL, &rel)
+		if statusCode == http.StatusNotFound {
+			lastErr = err
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		pv, ok := rel.productVersion(productName)
+		if !ok {
+			return nil, fmt.Errorf("release %q is not a valid release of %s", tag, productName)
+		}
+		return pv, nil
+	}
+
+	return nil, &errors.VersionNotFoundError{
+		Product: productName,
+		Version: v.String(),
+		Err:     lastErr,
+	}
+}
+
+// productVersion converts the release into ProductVersion.
+// Builds are recorded without platform, to be located
+// via the archive name template.
+func (rel gitHubRelease) productVersion(productName string) (*ProductVersion, bool) {
+	if rel.Draft {
+		return nil, false
+	}
+	v, err := version.NewVersion(strings.TrimPrefix(rel.TagName, "v"))
+	if err != nil {
+		return nil, false
+	}
+
+	pv := &ProductVersion{
+		Name:             productName,
+		Version:          v,
+		TimestampCreated: rel.PublishedAt,
+		FileURLs:         make(map[string]string, len(rel.Assets)),
+	}
+
+	var gpgSigs, sigs []string
+	for _, asset := range rel.Assets {
+		pv.FileURLs[asset.Name] = asset.BrowserDownloadURL
+
+		switch {
+		case strings.HasSuffix(asset.Name, "_SHA256SUMS"):
+			pv.SHASUMS = asset.Name
+		case strings.HasSuffix(asset.Name, "_SHA256SUMS.gpgsig"):
+			gpgSigs = append(gpgSigs, asset.Name)
+		case strings.HasSuffix(asset.Name, ".sig"):
+			sigs = append(sigs, asset.Name)
+		default:
+			pv.Builds = append(pv.Builds, &ProductBuild{
+				Name:     productName,
+				Version:  v.String(),
+				Filename: asset.Name,
+				URL:      asset.BrowserDownloadURL,
+			})
+		}
+	}
+	// .sig may also be used for other kinds of signatures (e.g. cosign),
+	// so any PGP signatures published as .gpgsig take precedence
+	pv.SHASUMSSigs = append(gpgSigs, sigs...)
+
+	return pv, true
+}
+
+// getGitHubJSON decodes the response from the GitHub API
+// and returns its status code
+func (r *Releases) getGitHubJSON(ctx context.Context, u string, v interface{}) (int, error) {
+	client := httpclient.NewHTTPClient(r.logger)
+
+	r.logger.Printf("requesting %s", u)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request for %q: %w", u, err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, &errors.NetworkError{URL: u, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, &errors.NetworkError{
+			URL:        u,
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("failed to obtain releases from %q: %s", u, resp.Status),
+		}
+	}
+	r.logger.Printf("received %s", resp.Status)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, &errors.NetworkError{URL: u, Err: err}
+	}
+
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("%w: failed to unmarshal response: %q", err, string(body))
+	}
+
+	return resp.StatusCode, nil
+}
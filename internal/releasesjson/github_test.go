@@ -0,0 +1,188 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releasesjson
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/chushi-io/lf-install/errors"
+	"github.com/chushi-io/lf-install/internal/testutil"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/hashicorp/go-version"
+)
+
+// newGitHubTestServer serves releases of example/tofu in the GitHub API,
+// with v1.6.2 tagged with the "v" prefix and 1.6.1 without it
+func newGitHubTestServer(t *testing.T) *Releases {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	fw, err := zw.Create("tofu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write([]byte("binary")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive := buf.Bytes()
+
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	release := func(tag, v string) string {
+		download := ts.URL + "/download/" + tag + "/"
+		return fmt.Sprintf(`{
+  "tag_name": %q,
+  "published_at": "2024-03-01T12:00:00Z",
+  "assets": [
+    {"name": "tofu_%[2]s_SHA256SUMS.sig", "browser_download_url": "%[3]stofu_%[2]s_SHA256SUMS.sig"},
+    {"name": "tofu_%[2]s_SHA256SUMS", "browser_download_url": "%[3]stofu_%[2]s_SHA256SUMS"},
+    {"name": "tofu_%[2]s_SHA256SUMS.gpgsig", "browser_download_url": "%[3]stofu_%[2]s_SHA256SUMS.gpgsig"},
+    {"name": "tofu_%[2]s_%[4]s_%[5]s.zip", "browser_download_url": "%[3]stofu_%[2]s_%[4]s_%[5]s.zip"}
+  ]
+}`, tag, v, download, runtime.GOOS, runtime.GOARCH)
+	}
+
+	mux.HandleFunc("/repos/example/tofu/releases", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "1" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprintf(w, `[%s, %s, {"tag_name": "v1.7.0", "draft": true}, {"tag_name": "nightly"}]`,
+			release("v1.6.2", "1.6.2"), release("1.6.1", "1.6.1"))
+	})
+	mux.HandleFunc("/repos/example/tofu/releases/tags/v1.6.2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, release("v1.6.2", "1.6.2"))
+	})
+	mux.HandleFunc("/repos/example/tofu/releases/tags/1.6.1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, release("1.6.1", "1.6.1"))
+	})
+	mux.HandleFunc("/download/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(archive)
+	})
+
+	rels := NewReleases()
+	rels.SetLogger(testutil.TestLogger())
+	rels.BaseURL = ts.URL + "/repos/example/tofu"
+	rels.Backend = product.ReleasesBackendGitHub
+	return rels
+}
+
+func TestReleases_ListProductVersions_gitHub(t *testing.T) {
+	t.Parallel()
+
+	rels := newGitHubTestServer(t)
+	pvs, err := rels.ListProductVersions(context.Background(), "tofu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pvs) != 2 {
+		t.Fatalf("expected drafts and non-version tags to be skipped, given %d versions", len(pvs))
+	}
+	pv, ok := pvs["1.6.1"]
+	if !ok {
+		t.Fatal("expected 1.6.1 to be listed")
+	}
+	if pv.Name != "tofu" {
+		t.Fatalf("unexpected name: %q", pv.Name)
+	}
+	if pv.TimestampCreated == nil {
+		t.Fatal("expected release time to be recorded")
+	}
+	if pv.SHASUMS != "tofu_1.6.1_SHA256SUMS" {
+		t.Fatalf("unexpected checksums file: %q", pv.SHASUMS)
+	}
+	expectedSigs := []string{"tofu_1.6.1_SHA256SUMS.gpgsig", "tofu_1.6.1_SHA256SUMS.sig"}
+	if fmt.Sprint(pv.SHASUMSSigs) != fmt.Sprint(expectedSigs) {
+		t.Fatalf("expected signatures %q, given %q", expectedSigs, pv.SHASUMSSigs)
+	}
+	if len(pv.Builds) != 1 {
+		t.Fatalf("expected a single build, given %d", len(pv.Builds))
+	}
+	expectedURL := strings.TrimSuffix(rels.BaseURL, "/repos/example/tofu") +
+		"/download/1.6.1/tofu_1.6.1_SHA256SUMS"
+	if given := pv.FileURL(rels.DownloadBaseURL(), pv.SHASUMS); given != expectedURL {
+		t.Fatalf("expected checksums URL %q, given %q", expectedURL, given)
+	}
+}
+
+func TestReleases_GetProductVersion_gitHub(t *testing.T) {
+	t.Parallel()
+
+	rels := newGitHubTestServer(t)
+	ctx := context.Background()
+
+	for _, v := range []string{"1.6.2", "1.6.1"} {
+		pv, err := rels.GetProductVersion(ctx, "tofu", version.Must(version.NewVersion(v)))
+		if err != nil {
+			t.Fatalf("%s: %s", v, err)
+		}
+		if pv.Version.String() != v {
+			t.Fatalf("expected %s, given %s", v, pv.Version)
+		}
+	}
+
+	_, err := rels.GetProductVersion(ctx, "tofu", version.Must(version.NewVersion("1.5.0")))
+	var vnfErr *errors.VersionNotFoundError
+	if !stderrors.As(err, &vnfErr) {
+		t.Fatalf("expected VersionNotFoundError, given %#v", err)
+	}
+}
+
+func TestDownloader_DownloadAndUnpack_archiveNameTemplate(t *testing.T) {
+	t.Parallel()
+
+	rels := newGitHubTestServer(t)
+	ctx := context.Background()
+	pv, err := rels.GetProductVersion(ctx, "tofu", version.Must(version.NewVersion("1.6.2")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &Downloader{
+		Logger:              testutil.TestLogger(),
+		BaseURL:             rels.DownloadBaseURL(),
+		ArchiveNameTemplate: "{{.Name}}_{{.Version}}_{{.OS}}_{{.Arch}}.zip",
+	}
+	dir := t.TempDir()
+	_, err = d.DownloadAndUnpack(ctx, pv, dir, "")
+	if err == nil {
+		t.Fatal("expected archive served as application/octet-stream to be rejected")
+	}
+
+	d.AcceptOctetStream = true
+	up, err := d.DownloadAndUnpack(ctx, pv, dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedFilename := fmt.Sprintf("tofu_1.6.2_%s_%s.zip", runtime.GOOS, runtime.GOARCH)
+	if up.ArchiveFilename != expectedFilename {
+		t.Fatalf("expected %q to be downloaded, given %q", expectedFilename, up.ArchiveFilename)
+	}
+	assertContent(t, filepath.Join(dir, "tofu"), "binary")
+
+	d.ArchiveNameTemplate = "{{.Name}}_{{.Version}}_{{.OS}}_{{.Arch}}.tar.gz"
+	_, err = d.DownloadAndUnpack(ctx, pv, t.TempDir(), "")
+	var nbErr *errors.NoBuildError
+	if !stderrors.As(err, &nbErr) {
+		t.Fatalf("expected NoBuildError, given %#v", err)
+	}
+}
@@ -3,7 +3,13 @@
 
 package releasesjson
 
-import "github.com/hashicorp/go-version"
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/go-version"
+)
 
 // ProductVersion is a wrapper around a particular product version like
 // "consul 0.5.1". A ProductVersion may have one or more builds.
@@ -14,6 +20,31 @@ type ProductVersion struct {
 	SHASUMSSig  string           `json:"shasums_signature,omitempty"`
 	SHASUMSSigs []string         `json:"shasums_signatures,omitempty"`
 	Builds      ProductBuilds    `json:"builds"`
+
+	// TimestampCreated represents when the version was released
+	// (nil if the index doesn't record it)
+	TimestampCreated *time.Time `json:"timestamp_created,omitempty"`
+
+	// Yanked indicates that the version was withdrawn upstream
+	// and should not be installed unless explicitly requested
+	Yanked       bool   `json:"yanked,omitempty"`
+	YankedReason string `json:"yanked_reason,omitempty"`
+
+	// FileURLs maps names of files published along with the version
+	// to their URLs where those don't follow the directory structure
+	// of the releases API (e.g. assets of GitHub releases)
+	FileURLs map[string]string `json:"-"`
+}
+
+// FileURL returns URL of the named file published along with the version
+func (pv *ProductVersion) FileURL(baseURL, filename string) string {
+	if u, ok := pv.FileURLs[filename]; ok {
+		return u
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", baseURL,
+		url.PathEscape(pv.Name),
+		url.PathEscape(pv.Version.String()),
+		url.PathEscape(filename))
 }
 
 type ProductVersionsMap map[string]*ProductVersion
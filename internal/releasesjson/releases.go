@@ -13,7 +13,9 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/chushi-io/lf-install/errors"
 	"github.com/chushi-io/lf-install/internal/httpclient"
+	"github.com/chushi-io/lf-install/product"
 	"github.com/hashicorp/go-version"
 )
 
@@ -51,6 +53,10 @@ type ProductBuild struct {
 type Releases struct {
 	logger  *log.Logger
 	BaseURL string
+
+	// Backend represents the kind of service at BaseURL
+	// (defaults to product.ReleasesBackendIndex)
+	Backend product.ReleasesBackend
 }
 
 func NewReleases() *Releases {
@@ -64,7 +70,21 @@ func (r *Releases) SetLogger(logger *log.Logger) {
 	r.logger = logger
 }
 
+// DownloadBaseURL returns the base URL to download archives
+// and checksums from (see Downloader), which is empty if their URLs
+// are to be used as obtained from the backend
+func (r *Releases) DownloadBaseURL() string {
+	if r.isGitHub() {
+		return ""
+	}
+	return r.BaseURL
+}
+
 func (r *Releases) ListProductVersions(ctx context.Context, productName string) (ProductVersionsMap, error) {
+	if r.isGitHub() {
+		return r.listGitHubReleases(ctx, productName)
+	}
+
 	client := httpclient.NewHTTPClient(r.logger)
 
 	productIndexURL := fmt.Sprintf("%s/%s/index.json",
@@ -78,12 +98,16 @@ func (r *Releases) ListProductVersions(ctx context.Context, productName string)
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, &errors.NetworkError{URL: productIndexURL, Err: err}
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to obtain product versions from %q: %s ",
-			productIndexURL, resp.Status)
+		return nil, &errors.NetworkError{
+			URL:        productIndexURL,
+			StatusCode: resp.StatusCode,
+			Err: fmt.Errorf("failed to obtain product versions from %q: %s ",
+				productIndexURL, resp.Status),
+		}
 	}
 
 	contentType := resp.Header.Get("content-type")
@@ -122,6 +146,10 @@ func (r *Releases) ListProductVersions(ctx context.Context, productName string)
 }
 
 func (r *Releases) GetProductVersion(ctx context.Context, product string, version *version.Version) (*ProductVersion, error) {
+	if r.isGitHub() {
+		return r.getGitHubRelease(ctx, product, version)
+	}
+
 	client := httpclient.NewHTTPClient(r.logger)
 
 	indexURL := fmt.Sprintf("%s/%s/%s/index.json",
@@ -136,12 +164,24 @@ func (r *Releases) GetProductVersion(ctx context.Context, product string, versio
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, &errors.NetworkError{URL: indexURL, Err: err}
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to obtain product version from %q: %s ",
-			indexURL, resp.Status)
+		err := &errors.NetworkError{
+			URL:        indexURL,
+			StatusCode: resp.StatusCode,
+			Err: fmt.Errorf("failed to obtain product version from %q: %s ",
+				indexURL, resp.Status),
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, &errors.VersionNotFoundError{
+				Product: product,
+				Version: version.String(),
+				Err:     err,
+			}
+		}
+		return nil, err
 	}
 
 	contentType := resp.Header.Get("content-type")
@@ -0,0 +1,69 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releasesjson
+
+import (
+	"path"
+
+	"github.com/chushi-io/lf-install/product"
+)
+
+// ExtractRule maps archive members matching Pattern
+// (see path.Match) to the destination directory Dir
+type ExtractRule struct {
+	Pattern string
+	Dir     string
+}
+
+// ExtractDirs represents destination directories
+// for each kind of archive member
+type ExtractDirs struct {
+	BinDir        string
+	LicenseDir    string
+	CompletionDir string
+	ManDir        string
+}
+
+// ExtractRulesForMembers turns archive members declared by a product
+// into extraction rules. Members with no destination directory
+// are left out, which means they are skipped during extraction.
+func ExtractRulesForMembers(members []product.ArchiveMember, binaryName string, dirs ExtractDirs) []ExtractRule {
+	rules := make([]ExtractRule, 0, len(members))
+	for _, m := range members {
+		rule := ExtractRule{Pattern: m.Pattern}
+		switch m.Kind {
+		case product.ArchiveMemberBinary:
+			if rule.Pattern == "" {
+				rule.Pattern = binaryName
+			}
+			rule.Dir = dirs.BinDir
+		case product.ArchiveMemberLicense:
+			rule.Dir = dirs.LicenseDir
+			if rule.Dir == "" {
+				rule.Dir = dirs.BinDir
+			}
+		case product.ArchiveMemberCompletion:
+			rule.Dir = dirs.CompletionDir
+		case product.ArchiveMemberManPage:
+			rule.Dir = dirs.ManDir
+		}
+
+		if rule.Dir == "" {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// matchRule returns destination directory for the given archive member
+// and whether it is to be extracted at all
+func matchRule(rules []ExtractRule, name string) (string, bool) {
+	for _, rule := range rules {
+		if ok, _ := path.Match(rule.Pattern, name); ok {
+			return rule.Dir, true
+		}
+	}
+	return "", false
+}
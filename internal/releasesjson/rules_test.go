@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releasesjson
+
+import (
+	"testing"
+
+	"github.com/chushi-io/lf-install/product"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExtractRulesForMembers(t *testing.T) {
+	members := []product.ArchiveMember{
+		{Kind: product.ArchiveMemberBinary},
+		{Pattern: "LICENSE*", Kind: product.ArchiveMemberLicense},
+		{Pattern: "completions/*", Kind: product.ArchiveMemberCompletion},
+		{Pattern: "man/*.1", Kind: product.ArchiveMemberManPage},
+	}
+
+	rules := ExtractRulesForMembers(members, "tofu", ExtractDirs{
+		BinDir: "/bin",
+		ManDir: "/man",
+	})
+	expectedRules := []ExtractRule{
+		{Pattern: "tofu", Dir: "/bin"},
+		{Pattern: "LICENSE*", Dir: "/bin"},
+		{Pattern: "man/*.1", Dir: "/man"},
+	}
+	if diff := cmp.Diff(expectedRules, rules); diff != "" {
+		t.Fatalf("unexpected rules: %s", diff)
+	}
+
+	testCases := map[string]struct {
+		dir string
+		ok  bool
+	}{
+		"tofu":                 {"/bin", true},
+		"LICENSE.txt":          {"/bin", true},
+		"man/tofu.1":           {"/man", true},
+		"completions/tofu.zsh": {"", false},
+		"README.md":            {"", false},
+	}
+	for name, tc := range testCases {
+		dir, ok := matchRule(rules, name)
+		if dir != tc.dir || ok != tc.ok {
+			t.Fatalf("%s: expected (%q, %t), got (%q, %t)", name, tc.dir, tc.ok, dir, ok)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releasesjson
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// stagingArea collects unpacked files in staging directories
+// created next to each destination directory (i.e. within the same
+// filesystem), so that they can be moved into place via atomic renames
+// and any previously installed files restored if that fails.
+type stagingArea struct {
+	logger *log.Logger
+
+	// keepBackups keeps backups of replaced files on commit,
+	// until either discardBackups or restoreBackups is called
+	keepBackups bool
+
+	// dirs maps destination directories to their staging directories
+	dirs    map[string]string
+	files   []stagedFile
+	dstDirs []string
+
+	// createdDirs tracks any destination directories created on commit
+	createdDirs []string
+}
+
+type stagedFile struct {
+	stagedPath string
+	dstPath    string
+	backupPath string
+	committed  bool
+}
+
+func newStagingArea(logger *log.Logger) *stagingArea {
+	return &stagingArea{
+		logger: logger,
+		dirs:   make(map[string]string, 0),
+	}
+}
+
+func (sa *stagingArea) stagingDir(dstDir string) (string, error) {
+	stagingDir, ok := sa.dirs[dstDir]
+	if !ok {
+		var err error
+		stagingDir, err = os.MkdirTemp(dstDir, ".lf-install-staging-*")
+		if err != nil {
+			return "", fmt.Errorf("unable to create staging directory: %w", err)
+		}
+		sa.logger.Printf("created staging dir at %s", stagingDir)
+		sa.dirs[dstDir] = stagingDir
+	}
+	return stagingDir, nil
+}
+
+// stagePath returns path within the staging directory of dstDir
+// where a file which is to end up at dstDir/name should be written.
+// name is expected to be a local (already sanitized) path.
+func (sa *stagingArea) stagePath(dstDir, name string) (string, error) {
+	stagingDir, err := sa.stagingDir(dstDir)
+	if err != nil {
+		return "", err
+	}
+
+	sf := stagedFile{
+		stagedPath: filepath.Join(stagingDir, name),
+		dstPath:    filepath.Join(dstDir, name),
+		backupPath: filepath.Join(stagingDir, ".lf-install-backup", name),
+	}
+
+	err = os.MkdirAll(filepath.Dir(sf.stagedPath), 0o755)
+	if err != nil {
+		return "", err
+	}
+
+	sa.files = append(sa.files, sf)
+
+	return sf.stagedPath, nil
+}
+
+// stagedPaths maps destination paths of all staged files
+// to paths they were staged at
+func (sa *stagingArea) stagedPaths() map[string]string {
+	paths := make(map[string]string, len(sa.files))
+	for _, sf := range sa.files {
+		paths[sf.dstPath] = sf.stagedPath
+	}
+	return paths
+}
+
+// stageDir records a directory to be created at dstDir/name
+// (which may end up empty otherwise)
+func (sa *stagingArea) stageDir(dstDir, name string) {
+	sa.dstDirs = append(sa.dstDirs, filepath.Join(dstDir, name))
+}
+
+// commit moves all staged files into their destination.
+// Any files previously present at the destination are restored
+// if any of the moves fail.
+//
+// Paths of all created files and directories are returned.
+func (sa *stagingArea) commit() ([]string, error) {
+	committedPaths := make([]string, 0, len(sa.files))
+
+	for _, dir := range sa.dstDirs {
+		if err := sa.mkdirAll(dir); err != nil {
+			sa.rollback()
+			return nil, err
+		}
+	}
+
+	for i := range sa.files {
+		sf := &sa.files[i]
+
+		if err := sa.mkdirAll(filepath.Dir(sf.dstPath)); err != nil {
+			sa.rollback()
+			return nil, err
+		}
+
+		if _, err := os.Lstat(sf.dstPath); err == nil {
+			sa.logger.Printf("backing up existing %s", sf.dstPath)
+			if err := os.MkdirAll(filepath.Dir(sf.backupPath), 0o700); err != nil {
+				sa.rollback()
+				return nil, err
+			}
+			if err := os.Rename(sf.dstPath, sf.backupPath); err != nil {
+				sa.rollback()
+				return nil, fmt.Errorf("unable to back up %q: %w", sf.dstPath, err)
+			}
+		} else {
+			sf.backupPath = ""
+		}
+
+		sa.logger.Printf("moving %s into place at %s", sf.stagedPath, sf.dstPath)
+		if err := os.Rename(sf.stagedPath, sf.dstPath); err != nil {
+			sf.restore(sa.logger)
+			sa.rollback()
+			return nil, fmt.Errorf("unable to move %q into place: %w", sf.dstPath, err)
+		}
+		sf.committed = true
+		committedPaths = append(committedPaths, sf.dstPath)
+	}
+
+	if !sa.keepBackups {
+		sa.cleanup()
+	}
+
+	return append(sa.createdDirs, committedPaths...), nil
+}
+
+// mkdirAll creates dir along with any missing parents
+// and keeps track of which directories were created
+func (sa *stagingArea) mkdirAll(dir string) error {
+	missing := make([]string, 0)
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Lstat(d); err == nil {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := os.Mkdir(missing[i], 0o755); err != nil {
+			return err
+		}
+		sa.createdDirs = append(sa.createdDirs, missing[i])
+	}
+
+	return nil
+}
+
+// rollback reverts any files which were already moved into place
+// and removes all staging directories
+func (sa *stagingArea) rollback() {
+	for i := len(sa.files) - 1; i >= 0; i-- {
+		sf := &sa.files[i]
+		if !sf.committed {
+			continue
+		}
+		if err := os.Remove(sf.dstPath); err != nil && !os.IsNotExist(err) {
+			sa.logger.Printf("unable to remove %s: %s", sf.dstPath, err)
+		}
+		sf.committed = false
+		sf.restore(sa.logger)
+	}
+	for i := len(sa.createdDirs) - 1; i >= 0; i-- {
+		if err := os.Remove(sa.createdDirs[i]); err != nil && !os.IsNotExist(err) {
+			sa.logger.Printf("unable to remove %s: %s", sa.createdDirs[i], err)
+		}
+	}
+	sa.createdDirs = nil
+	sa.cleanup()
+}
+
+// discardBackups removes backups kept on commit
+func (sa *stagingArea) discardBackups() {
+	sa.cleanup()
+}
+
+// restoreBackups reverts a commit, i.e. removes any committed files
+// and created directories and moves backups kept on commit back into place
+func (sa *stagingArea) restoreBackups() {
+	sa.rollback()
+}
+
+// cleanup removes all staging directories, including any backups
+func (sa *stagingArea) cleanup() {
+	for _, stagingDir := range sa.dirs {
+		if err := os.RemoveAll(stagingDir); err != nil {
+			sa.logger.Printf("unable to remove staging dir %s: %s", stagingDir, err)
+		}
+	}
+	sa.dirs = make(map[string]string, 0)
+}
+
+func (sf *stagedFile) restore(logger *log.Logger) {
+	if sf.backupPath == "" {
+		return
+	}
+	logger.Printf("restoring previous %s", sf.dstPath)
+	if err := os.Rename(sf.backupPath, sf.dstPath); err != nil {
+		logger.Printf("unable to restore %s: %s", sf.dstPath, err)
+		return
+	}
+	sf.backupPath = ""
+}
@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releasesjson
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chushi-io/lf-install/internal/testutil"
+)
+
+func TestStagingArea_commit(t *testing.T) {
+	dstDir := t.TempDir()
+	existingPath := filepath.Join(dstDir, "tofu")
+	if err := os.WriteFile(existingPath, []byte("old"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	sa := newStagingArea(testutil.TestLogger())
+	stagedPath, err := sa.stagePath(dstDir, "tofu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stagedPath, []byte("new"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := sa.commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || paths[0] != existingPath {
+		t.Fatalf("unexpected committed paths: %q", paths)
+	}
+
+	b, err := os.ReadFile(existingPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "new" {
+		t.Fatalf("expected new content, got %q", b)
+	}
+
+	assertDirEntries(t, dstDir, 1)
+}
+
+func TestStagingArea_rollback(t *testing.T) {
+	dstDir := t.TempDir()
+	existingPath := filepath.Join(dstDir, "tofu")
+	if err := os.WriteFile(existingPath, []byte("old"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	sa := newStagingArea(testutil.TestLogger())
+	stagedPath, err := sa.stagePath(dstDir, "tofu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stagedPath, []byte("new"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	// second file is never written, which makes the commit fail
+	_, err = sa.stagePath(dstDir, "LICENSE.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = sa.commit()
+	if err == nil {
+		t.Fatal("expected commit to fail")
+	}
+
+	b, err := os.ReadFile(existingPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "old" {
+		t.Fatalf("expected previous content to be restored, got %q", b)
+	}
+
+	assertDirEntries(t, dstDir, 1)
+}
+
+func assertDirEntries(t *testing.T, dir string, expected int) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != expected {
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Fatalf("expected %d entries in %s, got %q", expected, dir, names)
+	}
+}
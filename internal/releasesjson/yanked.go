@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releasesjson
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/chushi-io/lf-install/errors"
+	"github.com/chushi-io/lf-install/internal/httpclient"
+)
+
+// YankedVersions maps versions which were yanked (withdrawn)
+// to the reason (which may be empty)
+type YankedVersions map[string]string
+
+// yankedDocument represents yanked.json, i.e.
+//
+//	{"versions": {"1.6.1": "regression in state locking"}}
+type yankedDocument struct {
+	Versions YankedVersions `json:"versions"`
+}
+
+// ParseYankedVersions decodes yanked versions from the JSON document
+func ParseYankedVersions(r io.Reader) (YankedVersions, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var doc yankedDocument
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("unable to parse yanked versions: %w", err)
+	}
+	if doc.Versions == nil {
+		return YankedVersions{}, nil
+	}
+	return doc.Versions, nil
+}
+
+// Lookup returns the reason why the given version was yanked,
+// as recorded in the index or in yv
+func (yv YankedVersions) Lookup(pv *ProductVersion) (string, bool) {
+	if pv.Yanked {
+		return pv.YankedReason, true
+	}
+	if reason, ok := yv[pv.Version.Original()]; ok {
+		return reason, true
+	}
+	reason, ok := yv[pv.Version.String()]
+	return reason, ok
+}
+
+// Merge adds versions yanked in other
+func (yv YankedVersions) Merge(other YankedVersions) {
+	for v, reason := range other {
+		if existing, ok := yv[v]; !ok || existing == "" {
+			yv[v] = reason
+		}
+	}
+}
+
+// GetYankedVersions obtains versions listed in yanked.json
+// published next to index.json of the product. A missing file
+// (404 or 403, as returned by some object stores) means none,
+// as does the GitHub backend, which has no such file.
+func (r *Releases) GetYankedVersions(ctx context.Context, productName string) (YankedVersions, error) {
+	if r.isGitHub() {
+		r.logger.Printf("yanked versions are not published via GitHub releases")
+		return YankedVersions{}, nil
+	}
+
+	client := httpclient.NewHTTPClient(r.logger)
+
+	yankedURL := fmt.Sprintf("%s/%s/yanked.json",
+		r.BaseURL,
+		url.PathEscape(productName))
+	r.logger.Printf("requesting yanked versions from %s", yankedURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, yankedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %q: %w", yankedURL, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &errors.NetworkError{URL: yankedURL, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		r.logger.Printf("no yanked versions published (%s)", resp.Status)
+		return YankedVersions{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &errors.NetworkError{
+			URL:        yankedURL,
+			StatusCode: resp.StatusCode,
+			Err: fmt.Errorf("failed to obtain yanked versions from %q: %s",
+				yankedURL, resp.Status),
+		}
+	}
+
+	r.logger.Printf("received %s", resp.Status)
+
+	return ParseYankedVersions(resp.Body)
+}
@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releasesjson
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chushi-io/lf-install/internal/testutil"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-version"
+)
+
+func TestParseYankedVersions(t *testing.T) {
+	yanked, err := ParseYankedVersions(strings.NewReader(`{"versions": {"1.6.1": "regression", "1.6.2": ""}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := YankedVersions{"1.6.1": "regression", "1.6.2": ""}
+	if diff := cmp.Diff(expected, yanked); diff != "" {
+		t.Fatalf("unexpected yanked versions: %s", diff)
+	}
+
+	_, err = ParseYankedVersions(strings.NewReader(`{"yanked": ["1.6.1"]}`))
+	if err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+}
+
+func TestYankedVersions_Lookup(t *testing.T) {
+	yanked := YankedVersions{"1.6.1": "regression"}
+
+	testCases := map[string]struct {
+		pv             *ProductVersion
+		expectedYanked bool
+		expectedReason string
+	}{
+		"not-yanked": {
+			pv: &ProductVersion{Version: version.Must(version.NewVersion("1.6.0"))},
+		},
+		"yanked-in-list": {
+			pv:             &ProductVersion{Version: version.Must(version.NewVersion("1.6.1"))},
+			expectedYanked: true,
+			expectedReason: "regression",
+		},
+		"yanked-in-index": {
+			pv: &ProductVersion{
+				Version:      version.Must(version.NewVersion("1.6.2")),
+				Yanked:       true,
+				YankedReason: "broken build",
+			},
+			expectedYanked: true,
+			expectedReason: "broken build",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			reason, ok := yanked.Lookup(tc.pv)
+			if ok != tc.expectedYanked || reason != tc.expectedReason {
+				t.Fatalf("expected (%q, %t), got (%q, %t)", tc.expectedReason, tc.expectedYanked, reason, ok)
+			}
+		})
+	}
+}
+
+func TestGetYankedVersions(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tofu/yanked.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"versions": {"1.6.1": "regression"}}`))
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	r := NewReleases()
+	r.BaseURL = ts.URL
+	r.SetLogger(testutil.TestLogger())
+
+	yanked, err := r.GetYankedVersions(context.Background(), "tofu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(YankedVersions{"1.6.1": "regression"}, yanked); diff != "" {
+		t.Fatalf("unexpected yanked versions: %s", diff)
+	}
+
+	// missing file means no versions were yanked
+	yanked, err = r.GetYankedVersions(context.Background(), "bao")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(yanked) != 0 {
+		t.Fatalf("expected no yanked versions, got %v", yanked)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package product
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// ArchiveMember describes which members of a release archive
+// to extract and where to
+type ArchiveMember struct {
+	// Pattern is matched against the name of each archive member
+	// (see path.Match for syntax). Empty pattern of ArchiveMemberBinary
+	// matches the product's BinaryName.
+	Pattern string
+
+	// Kind determines the directory the member is extracted to
+	Kind ArchiveMemberKind
+}
+
+type ArchiveMemberKind int
+
+const (
+	// ArchiveMemberBinary is extracted to the install directory
+	ArchiveMemberBinary ArchiveMemberKind = iota
+
+	// ArchiveMemberLicense is extracted to the license directory,
+	// or install directory if no license directory is set
+	ArchiveMemberLicense
+
+	// ArchiveMemberCompletion represents shell completion scripts
+	// extracted to the completion directory (skipped if not set)
+	ArchiveMemberCompletion
+
+	// ArchiveMemberManPage represents man pages extracted
+	// to the man directory (skipped if not set)
+	ArchiveMemberManPage
+)
+
+func (k ArchiveMemberKind) String() string {
+	switch k {
+	case ArchiveMemberBinary:
+		return "binary"
+	case ArchiveMemberLicense:
+		return "license"
+	case ArchiveMemberCompletion:
+		return "completion"
+	case ArchiveMemberManPage:
+		return "man page"
+	}
+	return fmt.Sprintf("ArchiveMemberKind(%d)", int(k))
+}
+
+// ValidateArchiveMembers checks that all patterns are well-formed
+func ValidateArchiveMembers(members []ArchiveMember) error {
+	for _, m := range members {
+		if m.Pattern == "" && m.Kind != ArchiveMemberBinary {
+			return fmt.Errorf("missing pattern for %s archive member", m.Kind)
+		}
+		if _, err := path.Match(m.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid archive member pattern %q: %w", m.Pattern, err)
+		}
+	}
+	return nil
+}
+
+// ArchiveName represents data available to ArchiveNameTemplate
+type ArchiveName struct {
+	// Name represents name of the product
+	Name string
+
+	// Version represents the version, without any "v" prefix
+	Version string
+
+	// OS and Arch represent the platform, as GOOS and GOARCH
+	OS   string
+	Arch string
+}
+
+// ParseArchiveNameTemplate parses the template of archive names
+// (see Product.ArchiveNameTemplate)
+func ParseArchiveNameTemplate(tmpl string) (*template.Template, error) {
+	t, err := template.New("archive_name").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive name template: %w", err)
+	}
+	return t, nil
+}
+
+// RenderArchiveName renders name of the archive per the template
+// (see Product.ArchiveNameTemplate)
+func RenderArchiveName(tmpl string, data ArchiveName) (string, error) {
+	t, err := ParseArchiveNameTemplate(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("unable to render archive name: %w", err)
+	}
+	name := b.String()
+	if name == "" || path.Base(name) != name {
+		return "", fmt.Errorf("invalid archive name: %q", name)
+	}
+	return name, nil
+}
@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package product
+
+import (
+	"context"
+	"debug/buildinfo"
+	"fmt"
+	"os/exec"
+	"runtime/debug"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"golang.org/x/mod/module"
+)
+
+// BuildInfo describes where Go binaries of the product record their version,
+// such that it can be read from the file without executing it
+type BuildInfo struct {
+	// ModulePath represents path of the main module
+	// (e.g. github.com/opentofu/opentofu), whose version
+	// is used unless it's a development or pseudo-version
+	ModulePath string
+
+	// VersionVars represents fully qualified names of variables
+	// stamped with the version via -ldflags "-X name=value",
+	// which take precedence over the module version
+	VersionVars []string
+}
+
+// ReadBuildInfoVersion reads the version of the Go binary at path
+// from its embedded build information, without executing it
+func ReadBuildInfoVersion(path string, bi BuildInfo) (*VersionInfo, error) {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return versionInfoFromBuildInfo(info, bi)
+}
+
+func versionInfoFromBuildInfo(info *debug.BuildInfo, bi BuildInfo) (*VersionInfo, error) {
+	if bi.ModulePath != "" && info.Main.Path != bi.ModulePath {
+		return nil, fmt.Errorf("unexpected main module %q (expected %q)", info.Main.Path, bi.ModulePath)
+	}
+
+	settings := make(map[string]string, len(info.Settings))
+	for _, s := range info.Settings {
+		settings[s.Key] = s.Value
+	}
+
+	vi := &VersionInfo{
+		Revision: settings["vcs.revision"],
+	}
+	if goos, goarch := settings["GOOS"], settings["GOARCH"]; goos != "" && goarch != "" {
+		vi.Platform = goos + "_" + goarch
+	}
+
+	rawVersion, ok := ldflagsVar(settings["-ldflags"], bi.VersionVars)
+	if !ok {
+		mainVersion := info.Main.Version
+		if mainVersion == "" || mainVersion == "(devel)" || module.IsPseudoVersion(mainVersion) {
+			return nil, fmt.Errorf("no version recorded in build info of %s", info.Main.Path)
+		}
+		rawVersion = mainVersion
+	}
+
+	v, err := version.NewVersion(rawVersion)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse version %q: %w", rawVersion, err)
+	}
+	vi.Version = v
+
+	return vi, nil
+}
+
+// ldflagsVar returns value of the first of vars
+// set via -X in the given linker flags
+func ldflagsVar(ldflags string, vars []string) (string, bool) {
+	fields := strings.Fields(ldflags)
+	values := make(map[string]string, 0)
+	for i, f := range fields {
+		var assignment string
+		switch {
+		case f == "-X" && i+1 < len(fields):
+			assignment = fields[i+1]
+		case strings.HasPrefix(f, "-X="):
+			assignment = strings.TrimPrefix(f, "-X=")
+		default:
+			continue
+		}
+		name, value, ok := strings.Cut(strings.Trim(assignment, `'"`), "=")
+		if ok {
+			values[name] = value
+		}
+	}
+
+	for _, name := range vars {
+		if value, ok := values[name]; ok && value != "" {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+type execEnvKey struct{}
+
+// WithExecEnv returns a context which makes GetVersion of products
+// which honor it (see Product.HonorsExecEnv) execute binaries with
+// the given environment (in "key=value" form) instead of inheriting
+// the current one
+func WithExecEnv(ctx context.Context, env []string) context.Context {
+	return context.WithValue(ctx, execEnvKey{}, env)
+}
+
+// VersionCommand returns a command for obtaining version
+// honoring any environment set via WithExecEnv, to be used
+// by GetVersion of products which set HonorsExecEnv
+func VersionCommand(ctx context.Context, path string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, path, args...)
+	if env, ok := ctx.Value(execEnvKey{}).([]string); ok {
+		cmd.Env = env
+	}
+	return cmd
+}
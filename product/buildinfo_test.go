@@ -0,0 +1,105 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package product
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestVersionInfoFromBuildInfo(t *testing.T) {
+	t.Parallel()
+
+	bi := BuildInfo{
+		ModulePath:  "github.com/opentofu/opentofu",
+		VersionVars: []string{"github.com/opentofu/opentofu/version.Version"},
+	}
+
+	testCases := map[string]struct {
+		info            *debug.BuildInfo
+		expectedVersion string
+		expectErr       bool
+	}{
+		"ldflags": {
+			info: &debug.BuildInfo{
+				Main: debug.Module{Path: bi.ModulePath, Version: "(devel)"},
+				Settings: []debug.BuildSetting{
+					{Key: "-ldflags", Value: "-s -w -X github.com/opentofu/opentofu/version.dev=no -X github.com/opentofu/opentofu/version.Version=1.8.2"},
+					{Key: "GOOS", Value: "linux"},
+					{Key: "GOARCH", Value: "amd64"},
+					{Key: "vcs.revision", Value: "abc123"},
+				},
+			},
+			expectedVersion: "1.8.2",
+		},
+		"module-version": {
+			info: &debug.BuildInfo{
+				Main: debug.Module{Path: bi.ModulePath, Version: "v1.7.0"},
+			},
+			expectedVersion: "1.7.0",
+		},
+		"devel": {
+			info: &debug.BuildInfo{
+				Main: debug.Module{Path: bi.ModulePath, Version: "(devel)"},
+			},
+			expectErr: true,
+		},
+		"pseudo-version": {
+			info: &debug.BuildInfo{
+				Main: debug.Module{Path: bi.ModulePath, Version: "v0.0.0-20240101000000-abcdefabcdef"},
+			},
+			expectErr: true,
+		},
+		"unexpected-module": {
+			info: &debug.BuildInfo{
+				Main: debug.Module{Path: "github.com/example/other", Version: "v1.0.0"},
+			},
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			vi, err := versionInfoFromBuildInfo(tc.info, bi)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got version %s", vi.Version)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if vi.Version.String() != tc.expectedVersion {
+				t.Fatalf("expected version %s, got %s", tc.expectedVersion, vi.Version)
+			}
+		})
+	}
+}
+
+func TestVersionInfoFromBuildInfo_details(t *testing.T) {
+	t.Parallel()
+
+	vi, err := versionInfoFromBuildInfo(&debug.BuildInfo{
+		Main: debug.Module{Path: "github.com/openbao/openbao", Version: "v2.0.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "GOOS", Value: "darwin"},
+			{Key: "GOARCH", Value: "arm64"},
+			{Key: "vcs.revision", Value: "abc123"},
+		},
+	}, *OpenBao.BuildInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vi.Platform != "darwin_arm64" {
+		t.Fatalf("unexpected platform: %q", vi.Platform)
+	}
+	if vi.Revision != "abc123" {
+		t.Fatalf("unexpected revision: %q", vi.Revision)
+	}
+}
@@ -0,0 +1,423 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package product
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/chushi-io/lf-install/internal/build"
+	"github.com/chushi-io/lf-install/internal/validators"
+	"github.com/hashicorp/go-version"
+)
+
+// Definitions represents a JSON document declaring products
+//
+//	{
+//	  "products": [
+//	    {
+//	      "name": "mytool",
+//	      "aliases": ["my-tool"],
+//	      "binary_name": {"default": "mytool", "windows": "mytool.exe"},
+//	      "version": {"args": ["version"], "regex": "mytool v(?P<version>[0-9.]+)"},
+//	      "archive_members": [{"kind": "binary"}, {"pattern": "LICENSE*", "kind": "license"}],
+//	      "releases": {"base_url": "https://releases.example.com", "public_key_file": "key.asc"},
+//	      "build": {"git_repo_url": "https://github.com/example/mytool.git", "go_source_path": "./cmd/mytool"}
+//	    }
+//	  ]
+//	}
+type Definitions struct {
+	Products []Definition `json:"products"`
+}
+
+// Definition declares a product which can be turned into Product
+// without writing any Go code
+type Definition struct {
+	// Name represents the canonical name used for lookups in a Registry
+	Name string `json:"name"`
+
+	// Aliases represents any other names used for lookups in a Registry
+	Aliases []string `json:"aliases,omitempty"`
+
+	// ReleaseName identifies the product in the releases API
+	// (defaults to Name)
+	ReleaseName string `json:"release_name,omitempty"`
+
+	// BinaryName maps GOOS to binary name, with "default" used
+	// for any other OS. Defaults to the release name, with
+	// the .exe suffix on Windows.
+	BinaryName map[string]string `json:"binary_name,omitempty"`
+
+	// Version represents how to obtain the version from the binary
+	Version *VersionDefinition `json:"version,omitempty"`
+
+	// BuildInfo represents where Go binaries record their version
+	BuildInfo *BuildInfoDefinition `json:"build_info,omitempty"`
+
+	// ArchiveMembers represents which members of release archives to extract
+	ArchiveMembers []ArchiveMemberDefinition `json:"archive_members,omitempty"`
+
+	// Releases represents where releases are published and how they're signed
+	Releases *ReleasesDefinition `json:"releases,omitempty"`
+
+	// Build represents how to build the product from source using Go
+	Build *BuildDefinition `json:"build,omitempty"`
+
+	// Lifecycle represents support windows of minor versions
+	Lifecycle *Lifecycle `json:"lifecycle,omitempty"`
+}
+
+type VersionDefinition struct {
+	// Args represents arguments passed to the binary to print its version
+	Args []string `json:"args"`
+
+	// Regex is matched against the output. The version is taken from
+	// the group named "version", or the only group if there's no such group.
+	Regex string `json:"regex"`
+}
+
+type BuildInfoDefinition struct {
+	ModulePath  string   `json:"module_path,omitempty"`
+	VersionVars []string `json:"version_vars,omitempty"`
+}
+
+type ArchiveMemberDefinition struct {
+	Pattern string `json:"pattern,omitempty"`
+
+	// Kind is one of binary, license, completion, or man_page
+	Kind string `json:"kind"`
+}
+
+type ReleasesDefinition struct {
+	// Backend is one of index (default) or github
+	// (see ReleasesBackendIndex and ReleasesBackendGitHub)
+	Backend string `json:"backend,omitempty"`
+
+	// BaseURL represents URL of the releases API, i.e. with the same
+	// directory structure as releases.hashicorp.com for the index backend,
+	// or the repository in the GitHub API for the github backend
+	// (e.g. https://api.github.com/repos/example/mytool)
+	BaseURL string `json:"base_url,omitempty"`
+
+	// ArchiveNameTemplate represents name of release archives
+	// (see Product.ArchiveNameTemplate), required for the github backend
+	ArchiveNameTemplate string `json:"archive_name_template,omitempty"`
+
+	// ArmoredPublicKey is a public PGP key in ASCII/armor format
+	// used to verify signature of checksums
+	ArmoredPublicKey string `json:"armored_public_key,omitempty"`
+
+	// PublicKeyFile is a path to the key, as an alternative to ArmoredPublicKey.
+	// Relative paths are resolved against the directory of the definitions file.
+	PublicKeyFile string `json:"public_key_file,omitempty"`
+}
+
+type BuildDefinition struct {
+	GitRepoURL string `json:"git_repo_url"`
+
+	// GoSourcePath represents path to the main package
+	// passed to "go build" (defaults to the repository root)
+	GoSourcePath string `json:"go_source_path,omitempty"`
+
+	DetectVendoring bool `json:"detect_vendoring,omitempty"`
+
+	// CloneTimeout and BuildTimeout override default timeouts
+	// and are parsed via time.ParseDuration (e.g. "10m")
+	CloneTimeout string `json:"clone_timeout,omitempty"`
+	BuildTimeout string `json:"build_timeout,omitempty"`
+}
+
+// ParseDefinitions decodes product definitions from the JSON document
+func ParseDefinitions(r io.Reader) ([]Definition, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var defs Definitions
+	if err := dec.Decode(&defs); err != nil {
+		return nil, fmt.Errorf("unable to parse product definitions: %w", err)
+	}
+
+	return defs.Products, nil
+}
+
+// LoadDefinitionsFile reads product definitions from the JSON file at path
+func LoadDefinitionsFile(path string) ([]Definition, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	defs, err := ParseDefinitions(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for _, def := range defs {
+		if def.Releases != nil && def.Releases.PublicKeyFile != "" &&
+			!filepath.IsAbs(def.Releases.PublicKeyFile) {
+			def.Releases.PublicKeyFile = filepath.Join(dir, def.Releases.PublicKeyFile)
+		}
+	}
+
+	return defs, nil
+}
+
+// Product converts the definition into Product
+func (d Definition) Product() (Product, error) {
+	releaseName := d.ReleaseName
+	if releaseName == "" {
+		releaseName = d.Name
+	}
+	if !validators.IsProductNameValid(releaseName) {
+		return Product{}, fmt.Errorf("invalid product name: %q", releaseName)
+	}
+
+	p := Product{
+		Name:       releaseName,
+		BinaryName: binaryNameFunc(releaseName, d.BinaryName),
+	}
+	if binaryName := p.BinaryName(); !validators.IsBinaryNameValid(binaryName) {
+		return Product{}, fmt.Errorf("%s: invalid binary name: %q", d.Name, binaryName)
+	}
+
+	if d.Version != nil {
+		getVersion, err := getVersionFunc(d.Version)
+		if err != nil {
+			return Product{}, fmt.Errorf("%s: %w", d.Name, err)
+		}
+		p.GetVersion = getVersion
+		p.HonorsExecEnv = true
+	}
+
+	if d.BuildInfo != nil {
+		p.BuildInfo = &BuildInfo{
+			ModulePath:  d.BuildInfo.ModulePath,
+			VersionVars: d.BuildInfo.VersionVars,
+		}
+	}
+
+	for _, m := range d.ArchiveMembers {
+		kind, err := parseArchiveMemberKind(m.Kind)
+		if err != nil {
+			return Product{}, fmt.Errorf("%s: %w", d.Name, err)
+		}
+		p.ArchiveMembers = append(p.ArchiveMembers, ArchiveMember{
+			Pattern: m.Pattern,
+			Kind:    kind,
+		})
+	}
+	if err := ValidateArchiveMembers(p.ArchiveMembers); err != nil {
+		return Product{}, fmt.Errorf("%s: %w", d.Name, err)
+	}
+
+	if d.Releases != nil {
+		backend, err := parseReleasesBackend(d.Releases.Backend)
+		if err != nil {
+			return Product{}, fmt.Errorf("%s: %w", d.Name, err)
+		}
+		if d.Releases.ArchiveNameTemplate != "" {
+			if _, err := ParseArchiveNameTemplate(d.Releases.ArchiveNameTemplate); err != nil {
+				return Product{}, fmt.Errorf("%s: %w", d.Name, err)
+			}
+		}
+		if backend == ReleasesBackendGitHub {
+			if d.Releases.BaseURL == "" {
+				return Product{}, fmt.Errorf("%s: base_url is required for the github backend", d.Name)
+			}
+			if d.Releases.ArchiveNameTemplate == "" {
+				return Product{}, fmt.Errorf("%s: archive_name_template is required for the github backend", d.Name)
+			}
+		}
+
+		p.ReleasesBackend = backend
+		p.ReleasesBaseURL = d.Releases.BaseURL
+		p.ArchiveNameTemplate = d.Releases.ArchiveNameTemplate
+		p.ArmoredPublicKey = d.Releases.ArmoredPublicKey
+		if d.Releases.PublicKeyFile != "" {
+			if p.ArmoredPublicKey != "" {
+				return Product{}, fmt.Errorf("%s: armored_public_key and public_key_file are mutually exclusive", d.Name)
+			}
+			b, err := os.ReadFile(d.Releases.PublicKeyFile)
+			if err != nil {
+				return Product{}, fmt.Errorf("%s: unable to read public key: %w", d.Name, err)
+			}
+			p.ArmoredPublicKey = string(b)
+		}
+	}
+
+	if d.Lifecycle != nil {
+		lc := &Lifecycle{Cycles: append([]Cycle{}, d.Lifecycle.Cycles...)}
+		if err := lc.init(); err != nil {
+			return Product{}, fmt.Errorf("%s: %w", d.Name, err)
+		}
+		p.Lifecycle = lc
+	}
+
+	if d.Build != nil {
+		bi, err := buildInstructions(d.Build)
+		if err != nil {
+			return Product{}, fmt.Errorf("%s: %w", d.Name, err)
+		}
+		p.BuildInstructions = bi
+	}
+
+	return p, nil
+}
+
+func binaryNameFunc(releaseName string, names map[string]string) BinaryNameFunc {
+	return func() string {
+		if name, ok := names[runtime.GOOS]; ok {
+			return name
+		}
+		name, ok := names["default"]
+		if !ok {
+			name = releaseName
+		}
+		if runtime.GOOS == "windows" {
+			return name + ".exe"
+		}
+		return name
+	}
+}
+
+func getVersionFunc(vd *VersionDefinition) (func(context.Context, string) (*version.Version, error), error) {
+	re, err := regexp.Compile(vd.Regex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version regex: %w", err)
+	}
+
+	groupIdx := re.SubexpIndex("version")
+	if groupIdx < 0 {
+		if re.NumSubexp() != 1 {
+			return nil, fmt.Errorf("version regex must contain a group named \"version\" or exactly one group")
+		}
+		groupIdx = 1
+	}
+
+	args := vd.Args
+	return func(ctx context.Context, path string) (*version.Version, error) {
+		cmd := VersionCommand(ctx, path, args...)
+
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, err
+		}
+
+		stdout := strings.TrimSpace(string(out))
+
+		submatches := re.FindStringSubmatch(stdout)
+		if submatches == nil {
+			return nil, fmt.Errorf("no version found in %s", stdout)
+		}
+		v, err := version.NewVersion(submatches[groupIdx])
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse version %q: %w", submatches[groupIdx], err)
+		}
+
+		return v, nil
+	}, nil
+}
+
+func parseArchiveMemberKind(kind string) (ArchiveMemberKind, error) {
+	switch kind {
+	case "binary":
+		return ArchiveMemberBinary, nil
+	case "license":
+		return ArchiveMemberLicense, nil
+	case "completion":
+		return ArchiveMemberCompletion, nil
+	case "man_page":
+		return ArchiveMemberManPage, nil
+	}
+	return 0, fmt.Errorf("unknown archive member kind %q", kind)
+}
+
+func parseReleasesBackend(backend string) (ReleasesBackend, error) {
+	switch ReleasesBackend(backend) {
+	case "":
+		return ReleasesBackendIndex, nil
+	case ReleasesBackendIndex, ReleasesBackendGitHub:
+		return ReleasesBackend(backend), nil
+	}
+	return "", fmt.Errorf("unknown releases backend %q", backend)
+}
+
+func buildInstructions(bd *BuildDefinition) (*BuildInstructions, error) {
+	if bd.GitRepoURL == "" {
+		return nil, fmt.Errorf("git_repo_url is required to build")
+	}
+
+	bi := &BuildInstructions{
+		GitRepoURL:    bd.GitRepoURL,
+		PreCloneCheck: &build.GoIsInstalled{},
+		Build: &build.GoBuild{
+			DetectVendoring: bd.DetectVendoring,
+			SourcePath:      bd.GoSourcePath,
+		},
+	}
+
+	var err error
+	if bd.CloneTimeout != "" {
+		bi.CloneTimeout, err = time.ParseDuration(bd.CloneTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid clone_timeout: %w", err)
+		}
+	}
+	if bd.BuildTimeout != "" {
+		bi.BuildTimeout, err = time.ParseDuration(bd.BuildTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid build_timeout: %w", err)
+		}
+	}
+
+	return bi, nil
+}
+
+// RegisterDefinitions converts all definitions into products
+// and registers them under their names and aliases.
+// Nothing is registered unless all definitions are valid
+// and none of their names conflict.
+func (r *Registry) RegisterDefinitions(defs []Definition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	products := make([]Product, len(defs))
+	names := make([][]string, len(defs))
+	taken := make(map[string]bool, 0)
+	for i, d := range defs {
+		p, err := d.Product()
+		if err != nil {
+			return err
+		}
+		names[i], err = r.checkNames(d.Name, p, d.Aliases, taken)
+		if err != nil {
+			return err
+		}
+		for _, n := range names[i] {
+			taken[n] = true
+		}
+		products[i] = p
+	}
+
+	for i, p := range products {
+		r.add(names[i], p)
+	}
+	return nil
+}
+
+// RegisterDefinitions registers products declared by definitions
+// in the default registry (see Registry.RegisterDefinitions)
+func RegisterDefinitions(defs []Definition) error {
+	return defaultRegistry.RegisterDefinitions(defs)
+}
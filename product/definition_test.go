@@ -0,0 +1,189 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package product
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadDefinitionsFile(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "key.asc"), []byte("PUBLIC KEY"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "products.json")
+	err = os.WriteFile(path, []byte(`{
+  "products": [
+    {
+      "name": "mytool",
+      "aliases": ["my-tool"],
+      "release_name": "my-tool-releases",
+      "binary_name": {"default": "mytool"},
+      "version": {"args": ["--version"], "regex": "mytool v(?P<version>[0-9.]+)"},
+      "archive_members": [{"kind": "binary"}, {"pattern": "LICENSE*", "kind": "license"}],
+      "releases": {"base_url": "https://releases.example.com", "public_key_file": "key.asc"},
+      "build": {"git_repo_url": "https://example.com/mytool.git", "clone_timeout": "10m"}
+    }
+  ]
+}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defs, err := LoadDefinitionsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRegistry()
+	err = r.RegisterDefinitions(defs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, ok := r.Lookup("my-tool")
+	if !ok {
+		t.Fatal("expected product to be registered under alias")
+	}
+	if p.Name != "my-tool-releases" {
+		t.Fatalf("unexpected release name: %q", p.Name)
+	}
+	expectedBinaryName := "mytool"
+	if runtime.GOOS == "windows" {
+		expectedBinaryName = "mytool.exe"
+	}
+	if p.BinaryName() != expectedBinaryName {
+		t.Fatalf("unexpected binary name: %q", p.BinaryName())
+	}
+	if p.GetVersion == nil {
+		t.Fatal("expected GetVersion to be set")
+	}
+	if len(p.ArchiveMembers) != 2 || p.ArchiveMembers[1].Kind != ArchiveMemberLicense {
+		t.Fatalf("unexpected archive members: %#v", p.ArchiveMembers)
+	}
+	if p.ReleasesBackend != ReleasesBackendIndex {
+		t.Fatalf("expected index backend by default, given %q", p.ReleasesBackend)
+	}
+	if p.ReleasesBaseURL != "https://releases.example.com" {
+		t.Fatalf("unexpected releases URL: %q", p.ReleasesBaseURL)
+	}
+	if p.ArmoredPublicKey != "PUBLIC KEY" {
+		t.Fatalf("expected public key to be read from file, got %q", p.ArmoredPublicKey)
+	}
+	if p.BuildInstructions == nil || p.BuildInstructions.CloneTimeout != 10*time.Minute {
+		t.Fatalf("unexpected build instructions: %#v", p.BuildInstructions)
+	}
+}
+
+func TestDefinition_Product_gitHubReleases(t *testing.T) {
+	t.Parallel()
+
+	defs, err := ParseDefinitions(strings.NewReader(`{
+  "products": [
+    {
+      "name": "mytool",
+      "releases": {
+        "backend": "github",
+        "base_url": "https://api.github.com/repos/example/mytool",
+        "archive_name_template": "{{.Name}}_{{.Version}}_{{.OS}}_{{.Arch}}.zip"
+      }
+    }
+  ]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := defs[0].Product()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.ReleasesBackend != ReleasesBackendGitHub {
+		t.Fatalf("unexpected backend: %q", p.ReleasesBackend)
+	}
+	name, err := RenderArchiveName(p.ArchiveNameTemplate, ArchiveName{
+		Name:    p.Name,
+		Version: "1.2.3",
+		OS:      "linux",
+		Arch:    "arm64",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "mytool_1.2.3_linux_arm64.zip" {
+		t.Fatalf("unexpected archive name: %q", name)
+	}
+}
+
+func TestParseDefinitions_invalid(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"unknown-field":       `{"products": [{"name": "mytool", "unknown": true}]}`,
+		"invalid-name":        `{"products": [{"name": "my tool"}]}`,
+		"invalid-binary-name": `{"products": [{"name": "mytool", "binary_name": {"default": "my/tool"}}]}`,
+		"invalid-regex":       `{"products": [{"name": "mytool", "version": {"regex": "("}}]}`,
+		"ambiguous-regex":     `{"products": [{"name": "mytool", "version": {"regex": "(a)(b)"}}]}`,
+		"unknown-member-kind": `{"products": [{"name": "mytool", "archive_members": [{"kind": "docs"}]}]}`,
+		"missing-repo-url":    `{"products": [{"name": "mytool", "build": {}}]}`,
+		"invalid-timeout":     `{"products": [{"name": "mytool", "build": {"git_repo_url": "x", "build_timeout": "soon"}}]}`,
+		"unknown-backend":     `{"products": [{"name": "mytool", "releases": {"backend": "ftp"}}]}`,
+		"invalid-template":    `{"products": [{"name": "mytool", "releases": {"archive_name_template": "{{.Name"}}]}`,
+		"github-no-template":  `{"products": [{"name": "mytool", "releases": {"backend": "github", "base_url": "x"}}]}`,
+		"github-no-base-url":  `{"products": [{"name": "mytool", "releases": {"backend": "github", "archive_name_template": "x.zip"}}]}`,
+	}
+
+	for name, doc := range testCases {
+		name, doc := name, doc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			defs, err := ParseDefinitions(strings.NewReader(doc))
+			if err == nil {
+				err = NewRegistry().RegisterDefinitions(defs)
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			t.Log(err)
+		})
+	}
+}
+
+func TestRegistry_RegisterDefinitions_atomic(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"invalid-later":  `{"products": [{"name": "first"}, {"name": "second", "version": {"regex": "("}}]}`,
+		"alias-conflict": `{"products": [{"name": "first", "aliases": ["tool"]}, {"name": "second", "aliases": ["tool"]}]}`,
+	}
+
+	for name, doc := range testCases {
+		name, doc := name, doc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			defs, err := ParseDefinitions(strings.NewReader(doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := NewRegistry()
+			if err := r.RegisterDefinitions(defs); err == nil {
+				t.Fatal("expected error")
+			}
+			if names := r.Names(); len(names) != 0 {
+				t.Fatalf("expected nothing to be registered, given %q", names)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package product
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chushi-io/lf-install/errors"
+	"github.com/hashicorp/go-version"
+)
+
+//go:embed lifecycle/*.json
+var bundledLifecycles embed.FS
+
+const lifecycleDateLayout = "2006-01-02"
+
+// Lifecycle describes support windows of minor versions (cycles)
+// of a product, e.g.
+//
+//	{
+//	  "cycles": [
+//	    {"cycle": "1.6", "released": "2024-01-10", "end_of_support": "2025-01-09"},
+//	    {"cycle": "1.7", "released": "2024-04-30"}
+//	  ]
+//	}
+type Lifecycle struct {
+	Cycles []Cycle `json:"cycles"`
+}
+
+// Cycle represents a minor version of a product
+type Cycle struct {
+	// Cycle represents the major and minor version, e.g. "1.6"
+	Cycle string `json:"cycle"`
+
+	// Released represents the release date (YYYY-MM-DD) of the first version
+	Released string `json:"released,omitempty"`
+
+	// EndOfSupport represents the date (YYYY-MM-DD) since which
+	// the cycle is no longer supported (empty if still supported)
+	EndOfSupport string `json:"end_of_support,omitempty"`
+
+	major, minor int64
+	endOfSupport time.Time
+}
+
+// EndOfSupportAction determines what happens with versions
+// which are past end of support per Product.Lifecycle
+type EndOfSupportAction int
+
+const (
+	// EndOfSupportIgnore doesn't check end of support
+	EndOfSupportIgnore EndOfSupportAction = iota
+
+	// EndOfSupportWarn logs a warning
+	EndOfSupportWarn
+
+	// EndOfSupportFail rejects the version
+	// (see errors.EndOfSupportError)
+	EndOfSupportFail
+)
+
+// ParseLifecycle decodes a lifecycle from the JSON document
+func ParseLifecycle(r io.Reader) (*Lifecycle, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var lc Lifecycle
+	if err := dec.Decode(&lc); err != nil {
+		return nil, fmt.Errorf("unable to parse lifecycle: %w", err)
+	}
+	if err := lc.init(); err != nil {
+		return nil, err
+	}
+	return &lc, nil
+}
+
+// LoadLifecycleFile reads a lifecycle from the JSON file at path
+func LoadLifecycleFile(path string) (*Lifecycle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lc, err := ParseLifecycle(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return lc, nil
+}
+
+// bundledLifecycle returns the lifecycle bundled for the product
+func bundledLifecycle(name string) *Lifecycle {
+	b, err := bundledLifecycles.ReadFile(path.Join("lifecycle", name+".json"))
+	if err != nil {
+		panic(fmt.Sprintf("no bundled lifecycle for %s: %s", name, err))
+	}
+	lc, err := ParseLifecycle(bytes.NewReader(b))
+	if err != nil {
+		panic(fmt.Sprintf("invalid bundled lifecycle for %s: %s", name, err))
+	}
+	return lc
+}
+
+// init parses cycles and dates and sorts cycles
+func (lc *Lifecycle) init() error {
+	for i := range lc.Cycles {
+		c := &lc.Cycles[i]
+
+		major, minor, ok := strings.Cut(c.Cycle, ".")
+		var err error
+		if ok {
+			c.major, err = strconv.ParseInt(major, 10, 64)
+			if err == nil {
+				c.minor, err = strconv.ParseInt(minor, 10, 64)
+			}
+		}
+		if !ok || err != nil {
+			return fmt.Errorf("invalid cycle %q, expected major.minor", c.Cycle)
+		}
+
+		if c.Released != "" {
+			if _, err := time.Parse(lifecycleDateLayout, c.Released); err != nil {
+				return fmt.Errorf("cycle %s: invalid release date: %w", c.Cycle, err)
+			}
+		}
+		if c.EndOfSupport != "" {
+			c.endOfSupport, err = time.Parse(lifecycleDateLayout, c.EndOfSupport)
+			if err != nil {
+				return fmt.Errorf("cycle %s: invalid end of support date: %w", c.Cycle, err)
+			}
+		}
+	}
+
+	sort.SliceStable(lc.Cycles, func(i, j int) bool {
+		a, b := lc.Cycles[i], lc.Cycles[j]
+		return a.major < b.major || (a.major == b.major && a.minor < b.minor)
+	})
+	return nil
+}
+
+// Cycle returns the cycle of the version, if known
+func (lc *Lifecycle) Cycle(v *version.Version) (Cycle, bool) {
+	segments := v.Segments64()
+	for _, c := range lc.Cycles {
+		if c.major == segments[0] && c.minor == segments[1] {
+			return c, true
+		}
+	}
+	return Cycle{}, false
+}
+
+// CheckSupport returns errors.EndOfSupportError if the version is past
+// end of support at the given time. Versions older than the oldest
+// known cycle share its end of support, while versions of any other
+// unknown cycles are considered supported.
+func (lc *Lifecycle) CheckSupport(productName string, v *version.Version, at time.Time) error {
+	if lc == nil || len(lc.Cycles) == 0 {
+		return nil
+	}
+
+	c, ok := lc.Cycle(v)
+	if !ok {
+		oldest := lc.Cycles[0]
+		segments := v.Segments64()
+		if segments[0] > oldest.major || (segments[0] == oldest.major && segments[1] > oldest.minor) {
+			return nil
+		}
+		c = oldest
+	}
+
+	if c.endOfSupport.IsZero() || at.Before(c.endOfSupport) {
+		return nil
+	}
+	return &errors.EndOfSupportError{
+		Product:      productName,
+		Version:      v.String(),
+		Cycle:        c.Cycle,
+		EndOfSupport: c.endOfSupport,
+	}
+}
+
+// CheckEndOfSupport checks the version against the lifecycle of the product
+// (if any) per the action, logging a warning or returning an error
+func CheckEndOfSupport(logger *log.Logger, p Product, v *version.Version, action EndOfSupportAction) error {
+	if action == EndOfSupportIgnore || v == nil {
+		return nil
+	}
+
+	err := p.Lifecycle.CheckSupport(p.Name, v, time.Now())
+	if err == nil {
+		return nil
+	}
+	if action == EndOfSupportFail {
+		return err
+	}
+	logger.Printf("warning: %s", err)
+	return nil
+}
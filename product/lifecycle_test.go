@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package product
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	lferrors "github.com/chushi-io/lf-install/errors"
+	"github.com/hashicorp/go-version"
+)
+
+func TestLifecycle_CheckSupport(t *testing.T) {
+	t.Parallel()
+
+	lc, err := ParseLifecycle(strings.NewReader(`{
+  "cycles": [
+    {"cycle": "1.7", "released": "2024-04-30"},
+    {"cycle": "1.6", "released": "2024-01-10", "end_of_support": "2025-01-09"}
+  ]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		version       string
+		at            time.Time
+		expectedCycle string
+	}{
+		"supported": {
+			version: "1.7.2",
+			at:      at,
+		},
+		"before-end-of-support": {
+			version: "1.6.2",
+			at:      time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC),
+		},
+		"end-of-support": {
+			version:       "1.6.2",
+			at:            at,
+			expectedCycle: "1.6",
+		},
+		"older-than-known": {
+			version:       "1.5.7",
+			at:            at,
+			expectedCycle: "1.6",
+		},
+		"newer-than-known": {
+			version: "1.8.0-alpha1",
+			at:      at,
+		},
+	}
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := lc.CheckSupport("tofu", version.Must(version.NewVersion(tc.version)), tc.at)
+			if tc.expectedCycle == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %s", err)
+				}
+				return
+			}
+
+			var eosErr *lferrors.EndOfSupportError
+			if !errors.As(err, &eosErr) {
+				t.Fatalf("expected end of support error, got %v", err)
+			}
+			if eosErr.Cycle != tc.expectedCycle {
+				t.Fatalf("expected cycle %s, got %s", tc.expectedCycle, eosErr.Cycle)
+			}
+		})
+	}
+}
+
+func TestParseLifecycle_invalid(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"invalid-cycle": `{"cycles": [{"cycle": "1"}]}`,
+		"invalid-date":  `{"cycles": [{"cycle": "1.6", "end_of_support": "January 2025"}]}`,
+		"unknown-field": `{"cycles": [{"cycle": "1.6", "eol": "2025-01-09"}]}`,
+	}
+
+	for name, doc := range testCases {
+		name, doc := name, doc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := ParseLifecycle(strings.NewReader(doc)); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestBundledLifecycle(t *testing.T) {
+	t.Parallel()
+
+	if OpenTofu.Lifecycle == nil || len(OpenTofu.Lifecycle.Cycles) == 0 {
+		t.Fatal("expected bundled lifecycle of OpenTofu")
+	}
+	err := OpenTofu.Lifecycle.CheckSupport(OpenTofu.Name,
+		version.Must(version.NewVersion("1.6.0")), time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	if !errors.Is(err, lferrors.ErrEndOfSupport) {
+		t.Fatalf("expected 1.6 to be past end of support, got %v", err)
+	}
+}
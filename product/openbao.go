@@ -6,7 +6,6 @@ package product
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"regexp"
 	"runtime"
 	"strings"
@@ -28,7 +27,7 @@ var OpenBao = Product{
 		return "vault"
 	},
 	GetVersion: func(ctx context.Context, path string) (*version.Version, error) {
-		cmd := exec.CommandContext(ctx, path, "version")
+		cmd := VersionCommand(ctx, path, "version")
 
 		out, err := cmd.Output()
 		if err != nil {
@@ -48,9 +47,20 @@ var OpenBao = Product{
 
 		return v, err
 	},
+	HonorsExecEnv: true,
+	BuildInfo: &BuildInfo{
+		ModulePath:  "github.com/openbao/openbao",
+		VersionVars: []string{"github.com/openbao/openbao/version.Version"},
+	},
 	BuildInstructions: &BuildInstructions{
 		GitRepoURL:    "https://github.com/openbao/openbao.git",
 		PreCloneCheck: &build.GoIsInstalled{},
 		Build:         &build.GoBuild{},
 	},
+	ArchiveMembers: []ArchiveMember{
+		{Kind: ArchiveMemberBinary},
+		{Pattern: "LICENSE*", Kind: ArchiveMemberLicense},
+		{Pattern: "EULA.txt", Kind: ArchiveMemberLicense},
+		{Pattern: "TermsOfEvaluation.txt", Kind: ArchiveMemberLicense},
+	},
 }
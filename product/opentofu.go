@@ -5,8 +5,8 @@ package product
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"os/exec"
 	"regexp"
 	"runtime"
 	"strings"
@@ -30,29 +30,103 @@ var OpenTofu = Product{
 		return "tofu"
 	},
 	GetVersion: func(ctx context.Context, path string) (*version.Version, error) {
-		cmd := exec.CommandContext(ctx, path, "version")
-
-		out, err := cmd.Output()
+		vi, err := getTofuVersionInfo(ctx, path)
 		if err != nil {
 			return nil, err
 		}
-
-		stdout := strings.TrimSpace(string(out))
-
-		submatches := tofuVersionOutputRe.FindStringSubmatch(stdout)
-		if len(submatches) != 2 {
-			return nil, fmt.Errorf("unexpected number of version matches %d for %s", len(submatches), stdout)
-		}
-		v, err := version.NewVersion(submatches[1])
-		if err != nil {
-			return nil, fmt.Errorf("unable to parse version %q: %w", submatches[1], err)
-		}
-
-		return v, err
+		return vi.Version, nil
+	},
+	GetVersionInfo: getTofuVersionInfo,
+	HonorsExecEnv:  true,
+	// release builds embed the version from the VERSION file
+	// rather than stamping it via -ldflags, so only the module
+	// version (e.g. as set by "go install ...@v1.6.2") is recorded
+	BuildInfo: &BuildInfo{
+		ModulePath: "github.com/opentofu/opentofu",
 	},
 	BuildInstructions: &BuildInstructions{
 		GitRepoURL:    "https://github.com/opentofu/opentofu.git",
 		PreCloneCheck: &build.GoIsInstalled{},
 		Build:         &build.GoBuild{DetectVendoring: true, SourcePath: "cmd/tofu/*.go"},
 	},
+	ArchiveMembers: []ArchiveMember{
+		{Kind: ArchiveMemberBinary},
+		{Pattern: "LICENSE*", Kind: ArchiveMemberLicense},
+	},
+	Lifecycle: bundledLifecycle("tofu"),
+}
+
+// tofuVersionOutput represents output of "tofu version -json"
+type tofuVersionOutput struct {
+	Version            string            `json:"terraform_version"`
+	Platform           string            `json:"platform"`
+	ProviderSelections map[string]string `json:"provider_selections"`
+	Outdated           bool              `json:"terraform_outdated"`
+}
+
+// getTofuVersionInfo obtains version via "tofu version -json",
+// falling back to parsing the human-readable output
+// of "tofu version" for binaries without JSON support
+func getTofuVersionInfo(ctx context.Context, path string) (*VersionInfo, error) {
+	out, err := VersionCommand(ctx, path, "version", "-json").Output()
+	if err == nil {
+		vi, err := parseTofuVersionJSON(out)
+		if err == nil {
+			return vi, nil
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	out, err = VersionCommand(ctx, path, "version").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTofuVersionText(out)
+}
+
+func parseTofuVersionJSON(out []byte) (*VersionInfo, error) {
+	var vo tofuVersionOutput
+	err := json.Unmarshal(out, &vo)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := version.NewVersion(vo.Version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse version %q: %w", vo.Version, err)
+	}
+
+	vi := &VersionInfo{
+		Version:            v,
+		Platform:           vo.Platform,
+		ProviderSelections: make(map[string]*version.Version, len(vo.ProviderSelections)),
+		Outdated:           vo.Outdated,
+	}
+	for addr, rawVersion := range vo.ProviderSelections {
+		pv, err := version.NewVersion(rawVersion)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse version %q of provider %s: %w", rawVersion, addr, err)
+		}
+		vi.ProviderSelections[addr] = pv
+	}
+
+	return vi, nil
+}
+
+func parseTofuVersionText(out []byte) (*VersionInfo, error) {
+	stdout := strings.TrimSpace(string(out))
+
+	submatches := tofuVersionOutputRe.FindStringSubmatch(stdout)
+	if len(submatches) != 2 {
+		return nil, fmt.Errorf("unexpected number of version matches %d for %s", len(submatches), stdout)
+	}
+	v, err := version.NewVersion(submatches[1])
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse version %q: %w", submatches[1], err)
+	}
+
+	return &VersionInfo{Version: v}, nil
 }
@@ -0,0 +1,83 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package product
+
+import (
+	"testing"
+)
+
+func TestParseTofuVersionJSON(t *testing.T) {
+	t.Parallel()
+
+	out := []byte(`{
+  "terraform_version": "1.8.2",
+  "platform": "linux_amd64",
+  "provider_selections": {
+    "registry.opentofu.org/hashicorp/null": "3.2.2"
+  },
+  "terraform_outdated": true
+}`)
+
+	vi, err := parseTofuVersionJSON(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vi.Version.String() != "1.8.2" {
+		t.Fatalf("unexpected version: %s", vi.Version)
+	}
+	if vi.Platform != "linux_amd64" {
+		t.Fatalf("unexpected platform: %q", vi.Platform)
+	}
+	if !vi.Outdated {
+		t.Fatal("expected version to be reported as outdated")
+	}
+	pv, ok := vi.ProviderSelections["registry.opentofu.org/hashicorp/null"]
+	if !ok || pv.String() != "3.2.2" {
+		t.Fatalf("unexpected provider selections: %v", vi.ProviderSelections)
+	}
+
+	_, err = parseTofuVersionJSON([]byte("OpenTofu v1.6.0"))
+	if err == nil {
+		t.Fatal("expected human-readable output to be rejected as JSON")
+	}
+}
+
+func TestParseTofuVersionText(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		out             string
+		expectedVersion string
+	}{
+		"plain": {
+			out:             "OpenTofu v1.6.0\non linux_amd64\n",
+			expectedVersion: "1.6.0",
+		},
+		"outdated-warning": {
+			out: `OpenTofu v1.6.0
+on linux_amd64
+
+Your version of OpenTofu is out of date! The latest version
+is 1.8.2. You can update by downloading from https://opentofu.org/downloads
+`,
+			expectedVersion: "1.6.0",
+		},
+	}
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			vi, err := parseTofuVersionText([]byte(tc.out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if vi.Version.String() != tc.expectedVersion {
+				t.Fatalf("expected %s, got %s", tc.expectedVersion, vi.Version)
+			}
+		})
+	}
+}
@@ -22,12 +22,93 @@ type Product struct {
 	// reflecting any output or CLI flag differences
 	GetVersion func(ctx context.Context, execPath string) (*version.Version, error)
 
+	// GetVersionInfo represents how to obtain the version along with
+	// any other details reported by the product (optional)
+	GetVersionInfo func(ctx context.Context, execPath string) (*VersionInfo, error)
+
+	// HonorsExecEnv indicates that GetVersion and GetVersionInfo execute
+	// binaries with any environment set via WithExecEnv (e.g. by using
+	// VersionCommand). Binaries of other products are never executed
+	// where a scrubbed environment is required (see fs.DetectByBuildInfo).
+	HonorsExecEnv bool
+
+	// BuildInfo represents where the version is recorded in build
+	// information of the Go binary (optional, see ReadBuildInfoVersion)
+	BuildInfo *BuildInfo
+
 	// BuildInstructions represents how to build the product "from scratch"
 	BuildInstructions *BuildInstructions
+
+	// ArchiveMembers represents which members of release archives
+	// to extract and where to, any other members are skipped.
+	// If empty, all members are extracted to the install directory,
+	// except for well-known license files.
+	ArchiveMembers []ArchiveMember
+
+	// ReleasesBaseURL overrides URL of the releases API the product
+	// is downloaded from (ApiBaseURL of a source takes precedence)
+	ReleasesBaseURL string
+
+	// ReleasesBackend represents the kind of service at ReleasesBaseURL
+	// (defaults to ReleasesBackendIndex)
+	ReleasesBackend ReleasesBackend
+
+	// ArchiveNameTemplate represents name of the release archive
+	// for a platform as text/template (see ArchiveName), e.g.
+	// {{.Name}}_{{.Version}}_{{.OS}}_{{.Arch}}.zip
+	// (optional for ReleasesBackendIndex, which records
+	// the platform of each archive in its index)
+	ArchiveNameTemplate string
+
+	// ArmoredPublicKey overrides the built-in public PGP key (in ASCII/armor
+	// format) used to verify signature of downloaded checksums
+	// (ArmoredPublicKey of a source takes precedence)
+	ArmoredPublicKey string
+
+	// Lifecycle represents support windows of minor versions
+	// (optional, see CheckEndOfSupport)
+	Lifecycle *Lifecycle
 }
 
 type BinaryNameFunc func() string
 
+// ReleasesBackend represents a service products are released through
+type ReleasesBackend string
+
+const (
+	// ReleasesBackendIndex represents a releases API with the same
+	// directory structure as releases.hashicorp.com, including
+	// index.json files
+	ReleasesBackendIndex ReleasesBackend = "index"
+
+	// ReleasesBackendGitHub represents GitHub releases, with
+	// ReleasesBaseURL pointing to the repository in the GitHub API
+	// (e.g. https://api.github.com/repos/opentofu/opentofu).
+	// Archives are located via ArchiveNameTemplate and checksums via
+	// the *_SHA256SUMS asset along with its *_SHA256SUMS.gpgsig
+	// or *_SHA256SUMS.sig PGP signature.
+	ReleasesBackendGitHub ReleasesBackend = "github"
+)
+
+// VersionInfo represents version and related details reported by a binary
+type VersionInfo struct {
+	Version *version.Version
+
+	// Platform represents OS and architecture the binary
+	// was built for (e.g. linux_amd64), if reported
+	Platform string
+
+	// ProviderSelections represents versions of providers selected
+	// in the working directory, keyed by provider source address
+	ProviderSelections map[string]*version.Version
+
+	// Outdated indicates that the binary reported a newer version available
+	Outdated bool
+
+	// Revision represents VCS revision the binary was built from, if known
+	Revision string
+}
+
 type BuildInstructions struct {
 	GitRepoURL string
 
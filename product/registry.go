@@ -0,0 +1,139 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package product
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chushi-io/lf-install/internal/validators"
+)
+
+// Registry holds product definitions which can be
+// looked up by their canonical name or any alias
+type Registry struct {
+	mu       sync.RWMutex
+	products map[string]Product
+	aliases  map[string]string
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		products: make(map[string]Product),
+		aliases:  make(map[string]string),
+	}
+}
+
+// Register adds the product under the given canonical name
+// and any aliases. Names are case-insensitive and must not
+// already be registered.
+func (r *Registry) Register(name string, p Product, aliases ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names, err := r.checkNames(name, p, aliases, nil)
+	if err != nil {
+		return err
+	}
+	r.add(names, p)
+
+	return nil
+}
+
+// checkNames validates the product and returns its lowercased names
+// (canonical name first), none of which may be registered already
+// or be among taken names
+func (r *Registry) checkNames(name string, p Product, aliases []string, taken map[string]bool) ([]string, error) {
+	if !validators.IsProductNameValid(p.Name) {
+		return nil, fmt.Errorf("invalid product name: %q", p.Name)
+	}
+	if p.BinaryName == nil {
+		return nil, fmt.Errorf("product %q has no BinaryName", name)
+	}
+
+	names := append([]string{name}, aliases...)
+	for i, n := range names {
+		n = strings.ToLower(n)
+		if n == "" {
+			return nil, fmt.Errorf("empty name for product %q", name)
+		}
+		if _, ok := r.aliases[n]; ok || taken[n] {
+			return nil, fmt.Errorf("product %q is already registered", n)
+		}
+		names[i] = n
+	}
+	return names, nil
+}
+
+// add registers the product under names checked by checkNames
+func (r *Registry) add(names []string, p Product) {
+	canonicalName := names[0]
+	r.products[canonicalName] = p
+	for _, n := range names {
+		r.aliases[n] = canonicalName
+	}
+}
+
+// Lookup returns the product registered under the given name or alias
+func (r *Registry) Lookup(name string) (Product, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	canonicalName, ok := r.aliases[strings.ToLower(name)]
+	if !ok {
+		return Product{}, false
+	}
+	return r.products[canonicalName], true
+}
+
+// Names returns sorted canonical names of all registered products
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.products))
+	for name := range r.products {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultRegistry holds products known to this library
+// and any registered via Register
+var defaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	mustRegister(r, "tofu", OpenTofu, "opentofu")
+	mustRegister(r, "bao", OpenBao, "openbao")
+	return r
+}
+
+func mustRegister(r *Registry, name string, p Product, aliases ...string) {
+	if err := r.Register(name, p, aliases...); err != nil {
+		panic(err)
+	}
+}
+
+// Register adds the product to the default registry
+// (see Registry.Register)
+func Register(name string, p Product, aliases ...string) error {
+	return defaultRegistry.Register(name, p, aliases...)
+}
+
+// Lookup returns the product registered in the default registry
+// under the given name or alias
+func Lookup(name string) (Product, bool) {
+	return defaultRegistry.Lookup(name)
+}
+
+// Names returns sorted canonical names of all products
+// in the default registry
+func Names() []string {
+	return defaultRegistry.Names()
+}
@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package product
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		name         string
+		expectedName string
+		expectedOk   bool
+	}{
+		"tofu":     {name: "tofu", expectedName: OpenTofu.Name, expectedOk: true},
+		"opentofu": {name: "OpenTofu", expectedName: OpenTofu.Name, expectedOk: true},
+		"bao":      {name: "bao", expectedName: OpenBao.Name, expectedOk: true},
+		"openbao":  {name: "openbao", expectedName: OpenBao.Name, expectedOk: true},
+		"unknown":  {name: "terraform", expectedOk: false},
+	}
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p, ok := Lookup(tc.name)
+			if ok != tc.expectedOk {
+				t.Fatalf("expected lookup of %q to return %t", tc.name, tc.expectedOk)
+			}
+			if p.Name != tc.expectedName {
+				t.Fatalf("expected %q, got %q", tc.expectedName, p.Name)
+			}
+			if ok && p.GetVersion == nil {
+				t.Fatal("expected GetVersion to be preserved")
+			}
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	custom := Product{
+		Name:       "custom",
+		BinaryName: func() string { return "custom" },
+	}
+
+	err := r.Register("custom", custom, "my-custom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.Lookup("my-custom"); !ok {
+		t.Fatal("expected product to be found by alias")
+	}
+
+	err = r.Register("other", custom, "MY-CUSTOM")
+	expectedErr := fmt.Errorf("product %q is already registered", "my-custom")
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("expected error: %s, got: %v", expectedErr, err)
+	}
+	if _, ok := r.Lookup("other"); ok {
+		t.Fatal("expected failed registration to leave registry unchanged")
+	}
+
+	if names := r.Names(); len(names) != 1 || names[0] != "custom" {
+		t.Fatalf("unexpected names: %q", names)
+	}
+}
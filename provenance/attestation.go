@@ -0,0 +1,143 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package provenance verifies SLSA provenance of release archives,
+// recorded as in-toto attestations in DSSE envelopes signed by static keys.
+// Keyless (certificate-based) signatures, e.g. in Sigstore bundles,
+// are not verified.
+package provenance
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// PayloadType represents the DSSE payload type of in-toto statements
+	PayloadType = "application/vnd.in-toto+json"
+
+	// SLSA provenance predicate types
+	PredicateSLSAv02 = "https://slsa.dev/provenance/v0.2"
+	PredicateSLSAv1  = "https://slsa.dev/provenance/v1"
+)
+
+// Envelope represents a DSSE envelope carrying an in-toto statement
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+type Signature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   string `json:"sig"`
+}
+
+// Statement represents an in-toto statement
+type Statement struct {
+	Type          string          `json:"_type"`
+	Subject       []Subject       `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// Subject represents an artifact the statement is about
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// ParseAttestations decodes DSSE envelopes from a stream of JSON objects,
+// such as JSON Lines (e.g. multiple.intoto.jsonl published by
+// slsa-github-generator)
+func ParseAttestations(r io.Reader) ([]Envelope, error) {
+	dec := json.NewDecoder(r)
+
+	envelopes := make([]Envelope, 0)
+	for {
+		var env Envelope
+		err := dec.Decode(&env)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse attestations: %w", err)
+		}
+
+		if env.PayloadType == "" || env.Payload == "" {
+			return nil, fmt.Errorf("unable to parse attestations: not a DSSE envelope")
+		}
+		envelopes = append(envelopes, env)
+	}
+
+	return envelopes, nil
+}
+
+// LoadAttestationsFile reads envelopes from the file at path
+// (see ParseAttestations)
+func LoadAttestationsFile(path string) ([]Envelope, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	envelopes, err := ParseAttestations(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return envelopes, nil
+}
+
+// payload returns the decoded payload
+func (e Envelope) payload() ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		// some producers use URL-safe encoding
+		b, err = base64.URLEncoding.DecodeString(e.Payload)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload encoding: %w", err)
+	}
+	return b, nil
+}
+
+// Statement decodes the in-toto statement, without verifying signatures
+func (e Envelope) Statement() (*Statement, error) {
+	if e.PayloadType != PayloadType {
+		return nil, fmt.Errorf("unexpected payload type %q", e.PayloadType)
+	}
+	b, err := e.payload()
+	if err != nil {
+		return nil, err
+	}
+
+	var s Statement
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("unable to parse statement: %w", err)
+	}
+	return &s, nil
+}
+
+// pae returns the pre-authentication encoding of the payload,
+// which is what DSSE signatures are computed over
+func pae(payloadType string, payload []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+// hasDigest reports whether any subject has the given SHA-256 digest
+func (s *Statement) hasDigest(sha256Hex string) bool {
+	for _, subj := range s.Subject {
+		if subj.Digest["sha256"] == sha256Hex {
+			return true
+		}
+	}
+	return false
+}
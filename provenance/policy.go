@@ -0,0 +1,243 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provenance
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chushi-io/lf-install/errors"
+	"github.com/hashicorp/go-version"
+)
+
+// Policy determines which attestations are accepted as provenance
+// of a downloaded archive. An archive is accepted if any attestation
+// signed by one of PublicKeys is about its SHA-256 digest and
+// satisfies all of BuilderIDs, Repository and Tag (if set).
+type Policy struct {
+	// PublicKeys represents keys trusted to sign attestations
+	// (see LoadPublicKeys)
+	PublicKeys []crypto.PublicKey
+
+	// AllowUnsigned accepts attestations without verifying
+	// their signatures, which only makes sense when they're
+	// obtained from a trusted location (e.g. in tests)
+	AllowUnsigned bool
+
+	// BuilderIDs represents accepted builders. An ID without @
+	// matches the builder at any ref (e.g. any release of a reusable workflow).
+	BuilderIDs []string
+
+	// Repository represents the source repository the archive
+	// must be built from (e.g. github.com/opentofu/opentofu)
+	Repository string
+
+	// Tag represents the tag the archive must be built from,
+	// where {version} is replaced with the version (e.g. v{version})
+	Tag string
+
+	// File represents path to a local file with attestations
+	// (see ParseAttestations). It is required, as there is no
+	// common location where releases publish attestations.
+	File string
+}
+
+// Validate checks that the policy can be enforced
+func (p *Policy) Validate() error {
+	if len(p.PublicKeys) == 0 && !p.AllowUnsigned {
+		return fmt.Errorf("provenance policy requires public keys to verify attestations")
+	}
+	if p.File == "" {
+		return fmt.Errorf("provenance policy requires a file with attestations")
+	}
+	for _, k := range p.PublicKeys {
+		switch k.(type) {
+		case *ecdsa.PublicKey, ed25519.PublicKey, *rsa.PublicKey:
+		default:
+			return fmt.Errorf("unsupported public key type %T", k)
+		}
+	}
+	return nil
+}
+
+// Verify returns provenance of the archive with the given name
+// and SHA-256 checksum, if any of the envelopes satisfies the policy.
+// Otherwise errors.ProvenanceError is returned, describing why
+// each attestation of the archive was rejected.
+func (p *Policy) Verify(envelopes []Envelope, filename string, sum []byte, v *version.Version) (*Provenance, error) {
+	digest := hex.EncodeToString(sum)
+
+	reasons := make([]string, 0)
+	for _, env := range envelopes {
+		s, err := env.Statement()
+		if err != nil {
+			reasons = append(reasons, err.Error())
+			continue
+		}
+		if !s.hasDigest(digest) {
+			continue
+		}
+
+		if !p.AllowUnsigned {
+			if err := p.verifySignature(env); err != nil {
+				reasons = append(reasons, err.Error())
+				continue
+			}
+		}
+
+		prov, err := parseProvenance(s)
+		if err != nil {
+			reasons = append(reasons, err.Error())
+			continue
+		}
+		if err := p.check(prov, v); err != nil {
+			reasons = append(reasons, err.Error())
+			continue
+		}
+
+		return prov, nil
+	}
+
+	if len(reasons) == 0 {
+		reasons = append(reasons, fmt.Sprintf("no attestation found for sha256:%s", digest))
+	}
+	return nil, &errors.ProvenanceError{
+		Filename: filename,
+		Err:      fmt.Errorf("%s", strings.Join(reasons, "; ")),
+	}
+}
+
+// check verifies claims of the provenance against the policy
+func (p *Policy) check(prov *Provenance, v *version.Version) error {
+	if len(p.BuilderIDs) > 0 && !builderAllowed(p.BuilderIDs, prov.BuilderID) {
+		return fmt.Errorf("untrusted builder %q", prov.BuilderID)
+	}
+
+	if p.Repository != "" && prov.Repository != normalizeRepository(p.Repository) {
+		return fmt.Errorf("built from repository %q, expected %q", prov.Repository, p.Repository)
+	}
+
+	if p.Tag != "" {
+		tag := p.Tag
+		if v != nil {
+			tag = strings.ReplaceAll(tag, "{version}", v.String())
+		}
+		if prov.Ref != "refs/tags/"+tag {
+			return fmt.Errorf("built from ref %q, expected tag %q", prov.Ref, tag)
+		}
+	}
+
+	return nil
+}
+
+func builderAllowed(allowed []string, id string) bool {
+	for _, a := range allowed {
+		if a == id {
+			return true
+		}
+		if !strings.Contains(a, "@") && strings.HasPrefix(id, a+"@") {
+			return true
+		}
+	}
+	return false
+}
+
+// verifySignature checks that the envelope is signed by any of the keys
+func (p *Policy) verifySignature(env Envelope) error {
+	payload, err := env.payload()
+	if err != nil {
+		return err
+	}
+	msg := pae(env.PayloadType, payload)
+
+	for _, s := range env.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+		for _, k := range p.PublicKeys {
+			if verifyWithKey(k, msg, sig) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no valid signature by a trusted key")
+}
+
+func verifyWithKey(key crypto.PublicKey, msg, sig []byte) bool {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, msg, sig)
+	case *ecdsa.PublicKey:
+		var digest []byte
+		switch k.Curve {
+		case elliptic.P384():
+			h := sha512.Sum384(msg)
+			digest = h[:]
+		case elliptic.P521():
+			h := sha512.Sum512(msg)
+			digest = h[:]
+		default:
+			h := sha256.Sum256(msg)
+			digest = h[:]
+		}
+		return ecdsa.VerifyASN1(k, digest, sig)
+	case *rsa.PublicKey:
+		h := sha256.Sum256(msg)
+		if rsa.VerifyPKCS1v15(k, crypto.SHA256, h[:], sig) == nil {
+			return true
+		}
+		return rsa.VerifyPSS(k, crypto.SHA256, h[:], sig, nil) == nil
+	}
+	return false
+}
+
+// LoadPublicKeys parses PEM-encoded public keys (PKIX, i.e. "PUBLIC KEY" blocks)
+func LoadPublicKeys(pemData []byte) ([]crypto.PublicKey, error) {
+	keys := make([]crypto.PublicKey, 0)
+	for {
+		var block *pem.Block
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse public key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no public key found")
+	}
+	return keys, nil
+}
+
+// LoadPublicKeysFile reads PEM-encoded public keys from the file at path
+func LoadPublicKeysFile(path string) ([]crypto.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := LoadPublicKeys(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,219 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provenance
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	stderrors "errors"
+	"os"
+	"testing"
+
+	"github.com/chushi-io/lf-install/errors"
+	"github.com/hashicorp/go-version"
+)
+
+const builderID = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml"
+
+const attestationsPath = "testdata/tofu_1.6.2.intoto.jsonl"
+
+func testdata(t *testing.T) ([]Envelope, []crypto.PublicKey, []byte) {
+	t.Helper()
+
+	envelopes, err := LoadAttestationsFile(attestationsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := LoadPublicKeysFile("testdata/key.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := os.ReadFile("testdata/tofu_1.6.2.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(archive)
+	return envelopes, keys, sum[:]
+}
+
+func TestPolicy_Verify(t *testing.T) {
+	t.Parallel()
+
+	envelopes, keys, sum := testdata(t)
+	otherKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := version.Must(version.NewVersion("1.6.2"))
+
+	testCases := map[string]struct {
+		policy      Policy
+		sum         []byte
+		expectedErr bool
+	}{
+		"all-claims-match": {
+			policy: Policy{
+				PublicKeys: keys,
+				BuilderIDs: []string{builderID},
+				Repository: "https://github.com/opentofu/opentofu.git",
+				Tag:        "v{version}",
+			},
+			sum: sum,
+		},
+		"builder-at-exact-ref": {
+			policy: Policy{PublicKeys: keys, BuilderIDs: []string{builderID + "@refs/tags/v1.9.0"}},
+			sum:    sum,
+		},
+		"builder-at-other-ref": {
+			policy:      Policy{PublicKeys: keys, BuilderIDs: []string{builderID + "@refs/tags/v2.0.0"}},
+			sum:         sum,
+			expectedErr: true,
+		},
+		"untrusted-builder": {
+			policy:      Policy{PublicKeys: keys, BuilderIDs: []string{"https://example.com/builder"}},
+			sum:         sum,
+			expectedErr: true,
+		},
+		"other-repository": {
+			policy:      Policy{PublicKeys: keys, Repository: "github.com/example/fork"},
+			sum:         sum,
+			expectedErr: true,
+		},
+		"other-tag": {
+			policy:      Policy{PublicKeys: keys, Tag: "v1.6.3"},
+			sum:         sum,
+			expectedErr: true,
+		},
+		"untrusted-key": {
+			policy:      Policy{PublicKeys: []crypto.PublicKey{otherKey}},
+			sum:         sum,
+			expectedErr: true,
+		},
+		"unsigned-allowed": {
+			policy: Policy{AllowUnsigned: true, Repository: "github.com/opentofu/opentofu"},
+			sum:    sum,
+		},
+		"no-attestation-of-archive": {
+			policy:      Policy{PublicKeys: keys},
+			sum:         make([]byte, sha256.Size),
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tc.policy.File = attestationsPath
+			if err := tc.policy.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			prov, err := tc.policy.Verify(envelopes, "tofu_1.6.2_linux_amd64.zip", tc.sum, v)
+			if tc.expectedErr {
+				if !stderrors.Is(err, errors.ErrProvenanceInvalid) {
+					t.Fatalf("expected provenance error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if prov.Repository != "github.com/opentofu/opentofu" || prov.Ref != "refs/tags/v1.6.2" {
+				t.Fatalf("unexpected source: %s@%s", prov.Repository, prov.Ref)
+			}
+			if prov.Commit != "3d5c7bdd3c7a2a3c8d3d0a0e4c4fbb1b8bd6c1a2" {
+				t.Fatalf("unexpected commit: %q", prov.Commit)
+			}
+		})
+	}
+}
+
+func TestPolicy_Verify_tamperedPayload(t *testing.T) {
+	t.Parallel()
+
+	envelopes, keys, sum := testdata(t)
+	// replace the payload of the v0.2 envelope with that of the v1 one,
+	// such that the signature no longer matches
+	tampered := envelopes[1]
+	tampered.Payload = envelopes[0].Payload
+
+	p := Policy{PublicKeys: keys}
+	_, err := p.Verify([]Envelope{tampered}, "tofu_1.6.2_linux_amd64.zip", sum, nil)
+	if !stderrors.Is(err, errors.ErrProvenanceInvalid) {
+		t.Fatalf("expected provenance error, got %v", err)
+	}
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	t.Parallel()
+
+	p := Policy{Repository: "github.com/opentofu/opentofu", File: attestationsPath}
+	if err := p.Validate(); err == nil {
+		t.Fatal("expected error for policy without keys")
+	}
+
+	p = Policy{AllowUnsigned: true}
+	if err := p.Validate(); err == nil {
+		t.Fatal("expected error for policy without attestations file")
+	}
+}
+
+func TestParseProvenance_v02(t *testing.T) {
+	t.Parallel()
+
+	envelopes, _, _ := testdata(t)
+	s, err := envelopes[1].Statement()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prov, err := parseProvenance(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if prov.PredicateType != PredicateSLSAv02 {
+		t.Fatalf("unexpected predicate type: %q", prov.PredicateType)
+	}
+	if prov.BuilderID != builderID+"@refs/tags/v1.9.0" {
+		t.Fatalf("unexpected builder: %q", prov.BuilderID)
+	}
+	if prov.Repository != "github.com/opentofu/opentofu" || prov.Ref != "refs/tags/v1.6.2" {
+		t.Fatalf("unexpected source: %s@%s", prov.Repository, prov.Ref)
+	}
+}
+
+func TestSplitSourceURI(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		uri          string
+		expectedRepo string
+		expectedRef  string
+	}{
+		"https-with-tag": {
+			uri:          "git+https://github.com/opentofu/opentofu@refs/tags/v1.6.2",
+			expectedRepo: "github.com/opentofu/opentofu",
+			expectedRef:  "refs/tags/v1.6.2",
+		},
+		"ssh-with-userinfo": {
+			uri:          "git+ssh://git@github.com/OpenTofu/opentofu.git@refs/heads/main",
+			expectedRepo: "github.com/opentofu/opentofu",
+			expectedRef:  "refs/heads/main",
+		},
+		"no-ref": {
+			uri:          "git+ssh://git@github.com/opentofu/opentofu.git",
+			expectedRepo: "github.com/opentofu/opentofu",
+		},
+	}
+
+	for name, tc := range testCases {
+		repo, ref := splitSourceURI(tc.uri)
+		if repo != tc.expectedRepo || ref != tc.expectedRef {
+			t.Errorf("%s: expected %s@%s, given %s@%s", name, tc.expectedRepo, tc.expectedRef, repo, ref)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Provenance represents claims of a SLSA provenance predicate
+// (v0.2 or v1) relevant to policies
+type Provenance struct {
+	PredicateType string
+
+	// BuilderID identifies the builder (e.g. the reusable workflow
+	// https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml@refs/tags/v1.9.0)
+	BuilderID string
+
+	// Repository represents the source repository, normalized
+	// (e.g. github.com/opentofu/opentofu)
+	Repository string
+
+	// Ref represents the source ref (e.g. refs/tags/v1.6.2)
+	Ref string
+
+	// Commit represents the source commit, if recorded
+	Commit string
+}
+
+type slsaV02Predicate struct {
+	Builder struct {
+		ID string `json:"id"`
+	} `json:"builder"`
+	Invocation struct {
+		ConfigSource struct {
+			URI    string            `json:"uri"`
+			Digest map[string]string `json:"digest"`
+		} `json:"configSource"`
+	} `json:"invocation"`
+}
+
+type slsaV1Predicate struct {
+	BuildDefinition struct {
+		ExternalParameters struct {
+			Workflow *struct {
+				Repository string `json:"repository"`
+				Ref        string `json:"ref"`
+			} `json:"workflow"`
+		} `json:"externalParameters"`
+		ResolvedDependencies []struct {
+			URI    string            `json:"uri"`
+			Digest map[string]string `json:"digest"`
+		} `json:"resolvedDependencies"`
+	} `json:"buildDefinition"`
+	RunDetails struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+	} `json:"runDetails"`
+}
+
+// parseProvenance extracts claims from the SLSA provenance predicate
+func parseProvenance(s *Statement) (*Provenance, error) {
+	p := &Provenance{PredicateType: s.PredicateType}
+
+	switch s.PredicateType {
+	case PredicateSLSAv02:
+		var pred slsaV02Predicate
+		if err := json.Unmarshal(s.Predicate, &pred); err != nil {
+			return nil, fmt.Errorf("unable to parse SLSA v0.2 predicate: %w", err)
+		}
+		p.BuilderID = pred.Builder.ID
+		p.Repository, p.Ref = splitSourceURI(pred.Invocation.ConfigSource.URI)
+		p.Commit = gitCommit(pred.Invocation.ConfigSource.Digest)
+	case PredicateSLSAv1:
+		var pred slsaV1Predicate
+		if err := json.Unmarshal(s.Predicate, &pred); err != nil {
+			return nil, fmt.Errorf("unable to parse SLSA v1 predicate: %w", err)
+		}
+		p.BuilderID = pred.RunDetails.Builder.ID
+		if wf := pred.BuildDefinition.ExternalParameters.Workflow; wf != nil {
+			p.Repository, p.Ref = normalizeRepository(wf.Repository), wf.Ref
+		}
+		// resolved dependencies record the commit (and the source,
+		// for build types other than GitHub Actions workflows)
+		for _, dep := range pred.BuildDefinition.ResolvedDependencies {
+			if !strings.HasPrefix(dep.URI, "git+") {
+				continue
+			}
+			repo, ref := splitSourceURI(dep.URI)
+			if p.Repository == "" {
+				p.Repository, p.Ref = repo, ref
+			}
+			if repo == p.Repository {
+				p.Commit = gitCommit(dep.Digest)
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported predicate type %q", s.PredicateType)
+	}
+
+	return p, nil
+}
+
+// splitSourceURI splits URI of the source
+// (e.g. git+https://github.com/opentofu/opentofu@refs/tags/v1.6.2)
+// into the normalized repository and ref
+func splitSourceURI(uri string) (string, string) {
+	uri = strings.TrimPrefix(uri, "git+")
+
+	// the ref follows the last @ in the path, i.e. not in userinfo
+	pathIdx := 0
+	if i := strings.Index(uri, "://"); i >= 0 {
+		pathIdx = i + 3
+	}
+	if i := strings.Index(uri[pathIdx:], "/"); i >= 0 {
+		pathIdx += i
+	}
+	var ref string
+	if i := strings.LastIndex(uri, "@"); i > pathIdx {
+		uri, ref = uri[:i], uri[i+1:]
+	}
+
+	return normalizeRepository(uri), ref
+}
+
+// normalizeRepository strips scheme, userinfo and .git suffix
+// from the repository URL (e.g. github.com/opentofu/opentofu)
+func normalizeRepository(repo string) string {
+	repo = strings.TrimPrefix(repo, "git+")
+	if _, rest, ok := strings.Cut(repo, "://"); ok {
+		repo = rest
+	}
+	if host, path, ok := strings.Cut(repo, "/"); ok {
+		if _, h, ok := strings.Cut(host, "@"); ok {
+			repo = h + "/" + path
+		}
+	}
+	repo = strings.TrimSuffix(repo, "/")
+	repo = strings.TrimSuffix(repo, ".git")
+	return strings.ToLower(repo)
+}
+
+func gitCommit(digest map[string]string) string {
+	if c, ok := digest["gitCommit"]; ok {
+		return c
+	}
+	return digest["sha1"]
+}
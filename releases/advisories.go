@@ -0,0 +1,37 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releases
+
+import (
+	"log"
+	"strings"
+
+	"github.com/chushi-io/lf-install/advisory"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/chushi-io/lf-install/src"
+	"github.com/hashicorp/go-version"
+)
+
+// checkAdvisories returns advisories affecting the version (logging them),
+// or an error if the policy refuses to install affected versions
+func checkAdvisories(logger *log.Logger, policy *advisory.Policy, p product.Product, v *version.Version) ([]src.Advisory, error) {
+	if policy == nil {
+		return nil, nil
+	}
+
+	advisories := policy.Check(p, v)
+	for _, a := range advisories {
+		id := a.ID
+		if len(a.Aliases) > 0 {
+			id += " (" + strings.Join(a.Aliases, ", ") + ")"
+		}
+		logger.Printf("warning: %s %s is affected by %s: %s", p.Name, v, id, a.Summary)
+	}
+
+	err := policy.Enforce(p, v, advisories)
+	if err != nil {
+		return nil, err
+	}
+	return advisories, nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releases
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chushi-io/lf-install/advisory"
+	lferrors "github.com/chushi-io/lf-install/errors"
+	rjson "github.com/chushi-io/lf-install/internal/releasesjson"
+	"github.com/chushi-io/lf-install/internal/testutil"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/hashicorp/go-version"
+)
+
+func testAdvisoryDatabase(t *testing.T) *advisory.Database {
+	t.Helper()
+
+	dir := t.TempDir()
+	record := `{
+  "id": "GO-2024-0001",
+  "summary": "State file exposed via debug logs",
+  "affected": [{
+    "package": {"ecosystem": "Go", "name": "github.com/opentofu/opentofu"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "1.6.1"}, {"fixed": "1.6.3"}]}]
+  }]
+}`
+	err := os.WriteFile(filepath.Join(dir, "GO-2024-0001.json"), []byte(record), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := advisory.LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestLatestVersion_skipsVulnerable(t *testing.T) {
+	t.Parallel()
+
+	pvs := rjson.ProductVersionsMap{}
+	for _, raw := range []string{"1.6.0", "1.6.1", "1.6.2"} {
+		pvs[raw] = &rjson.ProductVersion{Version: version.Must(version.NewVersion(raw))}
+	}
+
+	lv := &LatestVersion{
+		Product: product.OpenTofu,
+		Advisories: &advisory.Policy{
+			Database: testAdvisoryDatabase(t),
+			Action:   advisory.Skip,
+		},
+	}
+	latest, ok := lv.findLatestMatchingVersion(pvs, nil)
+	if !ok {
+		t.Fatal("expected version to be found")
+	}
+	if latest.Version.String() != "1.6.0" {
+		t.Fatalf("expected 1.6.0, got %s", latest.Version)
+	}
+
+	// affected versions are selected unless skipped
+	lv.Advisories.Action = advisory.Warn
+	latest, _ = lv.findLatestMatchingVersion(pvs, nil)
+	if latest.Version.String() != "1.6.2" {
+		t.Fatalf("expected 1.6.2, got %s", latest.Version)
+	}
+}
+
+func TestExactVersion_refusesVulnerable(t *testing.T) {
+	t.Parallel()
+
+	ev := &ExactVersion{
+		Product:    product.OpenTofu,
+		Version:    version.Must(version.NewVersion("1.6.2")),
+		InstallDir: t.TempDir(),
+		ApiBaseURL: testutil.NewTestServer(t, yankMockApiRoot).URL,
+		Advisories: &advisory.Policy{
+			Database: testAdvisoryDatabase(t),
+			Action:   advisory.Fail,
+		},
+	}
+	ev.SetLogger(testutil.TestLogger())
+
+	_, err := ev.Install(context.Background())
+	if !errors.Is(err, lferrors.ErrVulnerableVersion) {
+		t.Fatalf("expected vulnerable version error, got %v", err)
+	}
+}
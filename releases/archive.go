@@ -0,0 +1,21 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releases
+
+import (
+	rjson "github.com/chushi-io/lf-install/internal/releasesjson"
+	"github.com/chushi-io/lf-install/product"
+)
+
+// extractRules returns rules for extraction of archive members declared
+// by the source (if any) or the product, or nil if none are declared
+func extractRules(p product.Product, members []product.ArchiveMember, dirs rjson.ExtractDirs) []rjson.ExtractRule {
+	if len(members) == 0 {
+		members = p.ArchiveMembers
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	return rjson.ExtractRulesForMembers(members, p.BinaryName(), dirs)
+}
@@ -11,11 +11,14 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/chushi-io/lf-install/advisory"
 	"github.com/chushi-io/lf-install/internal/pubkey"
 	rjson "github.com/chushi-io/lf-install/internal/releasesjson"
 	isrc "github.com/chushi-io/lf-install/internal/src"
 	"github.com/chushi-io/lf-install/internal/validators"
 	"github.com/chushi-io/lf-install/product"
+	"github.com/chushi-io/lf-install/provenance"
+	"github.com/chushi-io/lf-install/src"
 	"github.com/hashicorp/go-version"
 )
 
@@ -31,21 +34,64 @@ type ExactVersion struct {
 	// (required for enterprise versions, optional for Community editions).
 	LicenseDir string
 
+	// CompletionDir represents directory path where to install shell completion
+	// scripts declared in ArchiveMembers (skipped if empty)
+	CompletionDir string
+
+	// ManDir represents directory path where to install man pages
+	// declared in ArchiveMembers (skipped if empty)
+	ManDir string
+
+	// ArchiveMembers overrides ArchiveMembers of the Product, i.e. which
+	// members of the archive to extract and where to
+	ArchiveMembers []product.ArchiveMember
+
 	// Enterprise indicates installation of enterprise version (leave nil for Community editions)
 	Enterprise *EnterpriseOptions
 
 	SkipChecksumVerification bool
 
+	// VerifyVersion indicates whether to execute the unpacked binary
+	// (via GetVersion of the Product) and check that it reports
+	// the installed version before it is moved into place, such that
+	// any previously installed binary is kept on mismatch
+	VerifyVersion bool
+
+	// VerifyVersionTimeout overrides default timeout
+	// for the version verification
+	VerifyVersionTimeout time.Duration
+
 	// ArmoredPublicKey is a public PGP key in ASCII/armor format to use
 	// instead of built-in pubkey to verify signature of downloaded checksums
 	ArmoredPublicKey string
 
+	// YankPolicy determines where to look for yanked versions
+	// and whether to refuse installing them (warns by default)
+	YankPolicy YankPolicy
+
+	// Advisories checks the version against known vulnerabilities
+	// before installation, if set
+	Advisories *advisory.Policy
+
+	// Provenance verifies SLSA provenance of the downloaded
+	// archive before it is unpacked, if set
+	Provenance *provenance.Policy
+
 	// ApiBaseURL is an optional field that specifies a custom URL to download the product from.
 	// If ApiBaseURL is set, the product will be downloaded from this base URL instead of the default site.
 	// Note: The directory structure of the custom URL must match the HashiCorp releases site (including the index.json files).
 	ApiBaseURL    string
 	logger        *log.Logger
 	pathsToRemove []string
+	details       src.Details
+
+	keepBackups bool
+	unpacked    []*rjson.UnpackedProduct
+
+	yanked       bool
+	yankedReason string
+
+	postDownloadHook src.PostDownloadHookFunc
 }
 
 func (*ExactVersion) IsSourceImpl() isrc.InstallSrcSigil {
@@ -56,6 +102,24 @@ func (ev *ExactVersion) SetLogger(logger *log.Logger) {
 	ev.logger = logger
 }
 
+func (ev *ExactVersion) SetPostDownloadHook(hook src.PostDownloadHookFunc) {
+	ev.postDownloadHook = hook
+}
+
+// SetKeepBackups makes Install keep backups of any files it replaces
+// until Commit is called. Remove restores them until then.
+func (ev *ExactVersion) SetKeepBackups(keep bool) {
+	ev.keepBackups = keep
+}
+
+// Commit discards backups of files replaced by Install
+func (ev *ExactVersion) Commit() {
+	for _, up := range ev.unpacked {
+		up.DiscardBackups()
+	}
+	ev.unpacked = nil
+}
+
 func (ev *ExactVersion) log() *log.Logger {
 	if ev.logger == nil {
 		return discardLogger
@@ -80,6 +144,26 @@ func (ev *ExactVersion) Validate() error {
 		return err
 	}
 
+	if err := product.ValidateArchiveMembers(ev.ArchiveMembers); err != nil {
+		return err
+	}
+
+	if ev.VerifyVersion && ev.Product.GetVersion == nil {
+		return fmt.Errorf("GetVersion must be defined by the product to verify version")
+	}
+
+	if ev.Advisories != nil {
+		if err := ev.Advisories.Validate(ev.Product); err != nil {
+			return err
+		}
+	}
+
+	if ev.Provenance != nil {
+		if err := ev.Provenance.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -94,6 +178,7 @@ func (ev *ExactVersion) Install(ctx context.Context) (string, error) {
 	if ev.pathsToRemove == nil {
 		ev.pathsToRemove = make([]string, 0)
 	}
+	firstPathToRemove := len(ev.pathsToRemove)
 
 	dstDir := ev.InstallDir
 	if dstDir == "" {
@@ -109,6 +194,10 @@ func (ev *ExactVersion) Install(ctx context.Context) (string, error) {
 	ev.log().Printf("will install into dir at %s", dstDir)
 
 	rels := rjson.NewReleases()
+	if ev.Product.ReleasesBaseURL != "" {
+		rels.BaseURL = ev.Product.ReleasesBaseURL
+	}
+	rels.Backend = ev.Product.ReleasesBackend
 	if ev.ApiBaseURL != "" {
 		rels.BaseURL = ev.ApiBaseURL
 	}
@@ -122,30 +211,73 @@ func (ev *ExactVersion) Install(ctx context.Context) (string, error) {
 		return "", err
 	}
 
+	yanked, err := ev.YankPolicy.yankedVersions(ctx, rels, ev.Product.Name)
+	if err != nil {
+		return "", err
+	}
+	ev.yankedReason, ev.yanked = yanked.Lookup(pv)
+	pv.Yanked, pv.YankedReason = ev.yanked, ev.yankedReason
+	err = ev.YankPolicy.checkYanked(ev.log(), ev.Product.Name, pv)
+	if err != nil {
+		return "", err
+	}
+
+	advisories, err := checkAdvisories(ev.log(), ev.Advisories, ev.Product, pv.Version)
+	if err != nil {
+		return "", err
+	}
+
+	verifyProvenance, err := provenanceVerifier(ev.log(), ev.Provenance, pv)
+	if err != nil {
+		return "", err
+	}
+
 	d := &rjson.Downloader{
 		Logger:           ev.log(),
 		VerifyChecksum:   !ev.SkipChecksumVerification,
 		ArmoredPublicKey: pubkey.DefaultPublicKey,
-		BaseURL:          rels.BaseURL,
+		BaseURL:          rels.DownloadBaseURL(),
+		KeepBackups:      ev.keepBackups,
+
+		ArchiveNameTemplate: ev.Product.ArchiveNameTemplate,
+		AcceptOctetStream:   rels.Backend == product.ReleasesBackendGitHub,
+		VerifyArchive:       verifyProvenance,
+		Rules: extractRules(ev.Product, ev.ArchiveMembers, rjson.ExtractDirs{
+			BinDir:        dstDir,
+			LicenseDir:    ev.LicenseDir,
+			CompletionDir: ev.CompletionDir,
+			ManDir:        ev.ManDir,
+		}),
+	}
+	if ev.Product.ArmoredPublicKey != "" {
+		d.ArmoredPublicKey = ev.Product.ArmoredPublicKey
 	}
 	if ev.ArmoredPublicKey != "" {
 		d.ArmoredPublicKey = ev.ArmoredPublicKey
 	}
-	if ev.ApiBaseURL != "" {
-		d.BaseURL = ev.ApiBaseURL
+
+	if ev.postDownloadHook != nil {
+		d.PostDownload = func(ctx context.Context, archivePath string) error {
+			details := src.Details{Product: ev.Product.Name, Version: pv.Version}
+			return ev.postDownloadHook(ctx, details, archivePath)
+		}
+	}
+
+	execPath := filepath.Join(dstDir, ev.Product.BinaryName())
+	if ev.VerifyVersion {
+		d.VerifyUnpacked = stagedVersionVerifier(ev.log(), ev.Product, execPath, pv.Version, ev.VerifyVersionTimeout)
 	}
 
 	licenseDir := ev.LicenseDir
 	up, err := d.DownloadAndUnpack(ctx, pv, dstDir, licenseDir)
 	if up != nil {
 		ev.pathsToRemove = append(ev.pathsToRemove, up.PathsToRemove...)
+		ev.unpacked = append(ev.unpacked, up)
 	}
 	if err != nil {
 		return "", err
 	}
 
-	execPath := filepath.Join(dstDir, ev.Product.BinaryName())
-
 	ev.pathsToRemove = append(ev.pathsToRemove, execPath)
 
 	ev.log().Printf("changing perms of %s", execPath)
@@ -154,9 +286,32 @@ func (ev *ExactVersion) Install(ctx context.Context) (string, error) {
 		return "", err
 	}
 
+	ev.details = src.Details{
+		Version:    pv.Version,
+		Files:      append([]string{}, ev.pathsToRemove[firstPathToRemove:]...),
+		Advisories: advisories,
+		Download:   up.Download(),
+	}
+
 	return execPath, nil
 }
 
+// Details describes the version installed by the last Install call
+func (ev *ExactVersion) Details() src.Details {
+	details := ev.details
+	details.Product = ev.Product.Name
+	return details
+}
+
+// Yanked reports whether the version was found to be yanked (withdrawn)
+// upstream, either when listed via Versions or by the last Install call,
+// along with the reason (which may be empty)
+func (ev *ExactVersion) Yanked() (string, bool) {
+	return ev.yankedReason, ev.yanked
+}
+
+// Remove removes all files created by Install and restores
+// any files it replaced, unless already committed (see Commit)
 func (ev *ExactVersion) Remove(ctx context.Context) error {
 	if ev.pathsToRemove != nil {
 		for _, path := range ev.pathsToRemove {
@@ -166,6 +321,12 @@ func (ev *ExactVersion) Remove(ctx context.Context) error {
 			}
 		}
 	}
+	ev.pathsToRemove = nil
+
+	for i := len(ev.unpacked) - 1; i >= 0; i-- {
+		ev.unpacked[i].RestoreBackups()
+	}
+	ev.unpacked = nil
 
 	return nil
 }
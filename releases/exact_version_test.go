@@ -56,6 +56,17 @@ func TestExactVersionValidate(t *testing.T) {
 			},
 			expectedErr: fmt.Errorf("LicenseDir must be provided when requesting enterprise versions"),
 		},
+		"VerifyVersion-without-GetVersion": {
+			ev: ExactVersion{
+				Product: product.Product{
+					BinaryName: product.OpenTofu.BinaryName,
+					Name:       product.OpenTofu.Name,
+				},
+				Version:       version.Must(version.NewVersion("1.0.0")),
+				VerifyVersion: true,
+			},
+			expectedErr: fmt.Errorf("GetVersion must be defined by the product to verify version"),
+		},
 	}
 
 	for name, testCase := range testCases {
@@ -9,14 +9,17 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"sort"
 	"time"
 
+	"github.com/chushi-io/lf-install/advisory"
+	"github.com/chushi-io/lf-install/errors"
 	"github.com/chushi-io/lf-install/internal/pubkey"
 	rjson "github.com/chushi-io/lf-install/internal/releasesjson"
 	isrc "github.com/chushi-io/lf-install/internal/src"
 	"github.com/chushi-io/lf-install/internal/validators"
 	"github.com/chushi-io/lf-install/product"
+	"github.com/chushi-io/lf-install/provenance"
+	"github.com/chushi-io/lf-install/src"
 	"github.com/hashicorp/go-version"
 )
 
@@ -27,25 +30,87 @@ type LatestVersion struct {
 	Timeout            time.Duration
 	IncludePrereleases bool
 
+	// Selection determines which of the versions matching Constraints
+	// is installed (SelectHighest by default)
+	Selection Selection
+
+	// CurrentVersion represents the currently installed version,
+	// required by SelectLatestPatch
+	CurrentVersion *version.Version
+
+	// MinimumAge excludes versions released less than MinimumAge ago
+	// (or before AsOf, if set), e.g. to reduce supply-chain risk
+	MinimumAge time.Duration
+
+	// AsOf resolves Constraints as they would have resolved at the given
+	// time, excluding versions released later (e.g. to reproduce old builds)
+	//
+	// Versions without known release time are excluded
+	// when either MinimumAge or AsOf is set, and errors.ErrReleaseTimeUnavailable
+	// is returned if the releases API doesn't record release time of any
+	// version (which standard index.json files don't, unlike GitHub releases).
+	AsOf time.Time
+
 	// LicenseDir represents directory path where to install license files
 	// (required for enterprise versions, optional for Community editions).
 	LicenseDir string
 
+	// CompletionDir represents directory path where to install shell completion
+	// scripts declared in ArchiveMembers (skipped if empty)
+	CompletionDir string
+
+	// ManDir represents directory path where to install man pages
+	// declared in ArchiveMembers (skipped if empty)
+	ManDir string
+
+	// ArchiveMembers overrides ArchiveMembers of the Product, i.e. which
+	// members of the archive to extract and where to
+	ArchiveMembers []product.ArchiveMember
+
 	// Enterprise indicates installation of enterprise version (leave nil for Community editions)
 	Enterprise *EnterpriseOptions
 
 	SkipChecksumVerification bool
 
+	// VerifyVersion indicates whether to execute the unpacked binary
+	// (via GetVersion of the Product) and check that it reports
+	// the installed version before it is moved into place, such that
+	// any previously installed binary is kept on mismatch
+	VerifyVersion bool
+
+	// VerifyVersionTimeout overrides default timeout
+	// for the version verification
+	VerifyVersionTimeout time.Duration
+
 	// ArmoredPublicKey is a public PGP key in ASCII/armor format to use
 	// instead of built-in pubkey to verify signature of downloaded checksums
 	ArmoredPublicKey string
 
+	// YankPolicy determines where to look for yanked versions,
+	// which are never selected
+	YankPolicy YankPolicy
+
+	// Advisories checks versions against known vulnerabilities
+	// before installation, if set. Affected versions are not
+	// selected when the action is advisory.Skip.
+	Advisories *advisory.Policy
+
+	// Provenance verifies SLSA provenance of the downloaded
+	// archive before it is unpacked, if set
+	Provenance *provenance.Policy
+
 	// ApiBaseURL is an optional field that specifies a custom URL to download the product from.
 	// If ApiBaseURL is set, the product will be downloaded from this base URL instead of the default site.
 	// Note: The directory structure of the custom URL must match the HashiCorp releases site (including the index.json files).
 	ApiBaseURL    string
 	logger        *log.Logger
 	pathsToRemove []string
+	details       src.Details
+
+	keepBackups bool
+	unpacked    []*rjson.UnpackedProduct
+
+	postDownloadHook src.PostDownloadHookFunc
 }
 
 func (*LatestVersion) IsSourceImpl() isrc.InstallSrcSigil {
@@ -56,6 +121,24 @@ func (lv *LatestVersion) SetLogger(logger *log.Logger) {
 	lv.logger = logger
 }
 
+func (lv *LatestVersion) SetPostDownloadHook(hook src.PostDownloadHookFunc) {
+	lv.postDownloadHook = hook
+}
+
+// SetKeepBackups makes Install keep backups of any files it replaces
+// until Commit is called. Remove restores them until then.
+func (lv *LatestVersion) SetKeepBackups(keep bool) {
+	lv.keepBackups = keep
+}
+
+// Commit discards backups of files replaced by Install
+func (lv *LatestVersion) Commit() {
+	for _, up := range lv.unpacked {
+		up.DiscardBackups()
+	}
+	lv.unpacked = nil
+}
+
 func (lv *LatestVersion) log() *log.Logger {
 	if lv.logger == nil {
 		return discardLogger
@@ -76,6 +159,38 @@ func (lv *LatestVersion) Validate() error {
 		return err
 	}
 
+	if err := product.ValidateArchiveMembers(lv.ArchiveMembers); err != nil {
+		return err
+	}
+
+	if err := validateMinimumAge(lv.MinimumAge); err != nil {
+		return err
+	}
+
+	if lv.Advisories != nil {
+		if err := lv.Advisories.Validate(lv.Product); err != nil {
+			return err
+		}
+	}
+
+	if lv.Provenance != nil {
+		if err := lv.Provenance.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := selectionNames[lv.Selection]; !ok {
+		return fmt.Errorf("unknown selection strategy: %s", lv.Selection)
+	}
+
+	if lv.Selection == SelectLatestPatch && lv.CurrentVersion == nil {
+		return fmt.Errorf("CurrentVersion must be provided to select latest patch version")
+	}
+
+	if lv.VerifyVersion && lv.Product.GetVersion == nil {
+		return fmt.Errorf("GetVersion must be defined by the product to verify version")
+	}
+
 	return nil
 }
 
@@ -90,6 +205,7 @@ func (lv *LatestVersion) Install(ctx context.Context) (string, error) {
 	if lv.pathsToRemove == nil {
 		lv.pathsToRemove = make([]string, 0)
 	}
+	firstPathToRemove := len(lv.pathsToRemove)
 
 	dstDir := lv.InstallDir
 	if dstDir == "" {
@@ -105,6 +221,10 @@ func (lv *LatestVersion) Install(ctx context.Context) (string, error) {
 	lv.log().Printf("will install into dir at %s", dstDir)
 
 	rels := rjson.NewReleases()
+	if lv.Product.ReleasesBaseURL != "" {
+		rels.BaseURL = lv.Product.ReleasesBaseURL
+	}
+	rels.Backend = lv.Product.ReleasesBackend
 	if lv.ApiBaseURL != "" {
 		rels.BaseURL = lv.ApiBaseURL
 	}
@@ -115,37 +235,89 @@ func (lv *LatestVersion) Install(ctx context.Context) (string, error) {
 	}
 
 	if len(versions) == 0 {
-		return "", fmt.Errorf("no versions found for %q", lv.Product.Name)
+		return "", &errors.VersionNotFoundError{
+			Product: lv.Product.Name,
+			Err:     fmt.Errorf("no versions found for %q", lv.Product.Name),
+		}
+	}
+
+	yanked, err := lv.YankPolicy.yankedVersions(ctx, rels, lv.Product.Name)
+	if err != nil {
+		return "", err
+	}
+	markYanked(versions, yanked)
+
+	if _, checkReleaseTime := releaseCutoff(lv.MinimumAge, lv.AsOf); checkReleaseTime {
+		if err := checkReleaseTimesKnown(lv.Product.Name, versions); err != nil {
+			return "", err
+		}
 	}
 
 	versionToInstall, ok := lv.findLatestMatchingVersion(versions, lv.Constraints)
 	if !ok {
-		return "", fmt.Errorf("no matching version found for %q", lv.Constraints)
+		return "", &errors.VersionNotFoundError{
+			Product: lv.Product.Name,
+			Version: lv.Constraints.String(),
+			Err: fmt.Errorf("no matching version found for %q (selection: %s)",
+				lv.Constraints, lv.Selection),
+		}
+	}
+
+	advisories, err := checkAdvisories(lv.log(), lv.Advisories, lv.Product, versionToInstall.Version)
+	if err != nil {
+		return "", err
+	}
+
+	verifyProvenance, err := provenanceVerifier(lv.log(), lv.Provenance, versionToInstall)
+	if err != nil {
+		return "", err
 	}
 
 	d := &rjson.Downloader{
 		Logger:           lv.log(),
 		VerifyChecksum:   !lv.SkipChecksumVerification,
 		ArmoredPublicKey: pubkey.DefaultPublicKey,
-		BaseURL:          rels.BaseURL,
+		BaseURL:          rels.DownloadBaseURL(),
+		KeepBackups:      lv.keepBackups,
+
+		ArchiveNameTemplate: lv.Product.ArchiveNameTemplate,
+		AcceptOctetStream:   rels.Backend == product.ReleasesBackendGitHub,
+		VerifyArchive:       verifyProvenance,
+		Rules: extractRules(lv.Product, lv.ArchiveMembers, rjson.ExtractDirs{
+			BinDir:        dstDir,
+			LicenseDir:    lv.LicenseDir,
+			CompletionDir: lv.CompletionDir,
+			ManDir:        lv.ManDir,
+		}),
+	}
+	if lv.Product.ArmoredPublicKey != "" {
+		d.ArmoredPublicKey = lv.Product.ArmoredPublicKey
 	}
 	if lv.ArmoredPublicKey != "" {
 		d.ArmoredPublicKey = lv.ArmoredPublicKey
 	}
-	if lv.ApiBaseURL != "" {
-		d.BaseURL = lv.ApiBaseURL
+	if lv.postDownloadHook != nil {
+		d.PostDownload = func(ctx context.Context, archivePath string) error {
+			details := src.Details{Product: lv.Product.Name, Version: versionToInstall.Version}
+			return lv.postDownloadHook(ctx, details, archivePath)
+		}
+	}
+
+	execPath := filepath.Join(dstDir, lv.Product.BinaryName())
+	if lv.VerifyVersion {
+		d.VerifyUnpacked = stagedVersionVerifier(lv.log(), lv.Product, execPath, versionToInstall.Version, lv.VerifyVersionTimeout)
 	}
+
 	licenseDir := lv.LicenseDir
 	up, err := d.DownloadAndUnpack(ctx, versionToInstall, dstDir, licenseDir)
 	if up != nil {
 		lv.pathsToRemove = append(lv.pathsToRemove, up.PathsToRemove...)
+		lv.unpacked = append(lv.unpacked, up)
 	}
 	if err != nil {
 		return "", err
 	}
 
-	execPath := filepath.Join(dstDir, lv.Product.BinaryName())
-
 	lv.pathsToRemove = append(lv.pathsToRemove, execPath)
 
 	lv.log().Printf("changing perms of %s", execPath)
@@ -154,9 +326,25 @@ func (lv *LatestVersion) Install(ctx context.Context) (string, error) {
 		return "", err
 	}
 
+	lv.details = src.Details{
+		Version:    versionToInstall.Version,
+		Files:      append([]string{}, lv.pathsToRemove[firstPathToRemove:]...),
+		Advisories: advisories,
+		Download:   up.Download(),
+	}
+
 	return execPath, nil
 }
 
+// Details describes the version installed by the last Install call
+func (lv *LatestVersion) Details() src.Details {
+	details := lv.details
+	details.Product = lv.Product.Name
+	return details
+}
+
+// Remove removes all files created by Install and restores
+// any files it replaced, unless already committed (see Commit)
 func (lv *LatestVersion) Remove(ctx context.Context) error {
 	if lv.pathsToRemove != nil {
 		for _, path := range lv.pathsToRemove {
@@ -166,19 +354,37 @@ func (lv *LatestVersion) Remove(ctx context.Context) error {
 			}
 		}
 	}
+	lv.pathsToRemove = nil
+
+	for i := len(lv.unpacked) - 1; i >= 0; i-- {
+		lv.unpacked[i].RestoreBackups()
+	}
+	lv.unpacked = nil
 	return nil
 }
 
 func (lv *LatestVersion) findLatestMatchingVersion(pvs rjson.ProductVersionsMap, vc version.Constraints) (*rjson.ProductVersion, bool) {
 	expectedMetadata := enterpriseVersionMetadata(lv.Enterprise)
+	cutoff, checkReleaseTime := releaseCutoff(lv.MinimumAge, lv.AsOf)
 	versions := make(version.Collection, 0)
 	for _, pv := range pvs.AsSlice() {
-		if !lv.IncludePrereleases && pv.Version.Prerelease() != "" {
-			// skip prereleases if desired
+		if pv.Version.Metadata() != expectedMetadata {
 			continue
 		}
 
-		if pv.Version.Metadata() != expectedMetadata {
+		if checkReleaseTime && !releasedBy(pv, cutoff) {
+			lv.log().Printf("skipping %s, not released by %s", pv.Version, cutoff.Format(time.RFC3339))
+			continue
+		}
+
+		if pv.Yanked {
+			lv.log().Printf("skipping %s, yanked (reason: %q)", pv.Version, pv.YankedReason)
+			continue
+		}
+
+		if lv.Advisories != nil && lv.Advisories.Action == advisory.Skip &&
+			vc.Check(pv.Version) && len(lv.Advisories.Check(lv.Product, pv.Version)) > 0 {
+			lv.log().Printf("skipping %s, affected by known vulnerabilities", pv.Version)
 			continue
 		}
 
@@ -187,12 +393,10 @@ func (lv *LatestVersion) findLatestMatchingVersion(pvs rjson.ProductVersionsMap,
 		}
 	}
 
-	if len(versions) == 0 {
+	selectedVersion, ok := selectVersion(versions, lv.Selection, lv.CurrentVersion, lv.IncludePrereleases)
+	if !ok {
 		return nil, false
 	}
 
-	sort.Stable(versions)
-	latestVersion := versions[len(versions)-1]
-
-	return pvs[latestVersion.Original()], true
+	return pvs[selectedVersion.Original()], true
 }
@@ -6,6 +6,7 @@ package releases
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	rjson "github.com/chushi-io/lf-install/internal/releasesjson"
 	"github.com/chushi-io/lf-install/product"
@@ -49,6 +50,27 @@ func TestLatestVersionValidate(t *testing.T) {
 			},
 			expectedErr: fmt.Errorf("LicenseDir must be provided when requesting enterprise versions"),
 		},
+		"Selection-latest-patch-missing-current-version": {
+			lv: LatestVersion{
+				Product:   product.OpenTofu,
+				Selection: SelectLatestPatch,
+			},
+			expectedErr: fmt.Errorf("CurrentVersion must be provided to select latest patch version"),
+		},
+		"MinimumAge-negative": {
+			lv: LatestVersion{
+				Product:    product.OpenTofu,
+				MinimumAge: -time.Hour,
+			},
+			expectedErr: fmt.Errorf("MinimumAge must not be negative"),
+		},
+		"Selection-unknown": {
+			lv: LatestVersion{
+				Product:   product.OpenTofu,
+				Selection: Selection(42),
+			},
+			expectedErr: fmt.Errorf("unknown selection strategy: Selection(42)"),
+		},
 	}
 
 	for name, testCase := range testCases {
@@ -144,3 +166,179 @@ func TestLatestVersion_FindLatestMatchingVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestLatestVersion_Selection(t *testing.T) {
+	t.Parallel()
+
+	possibleVersions := rjson.ProductVersionsMap{}
+	for _, raw := range []string{"1.5.0", "1.5.7", "1.6.0", "1.6.1", "1.6.2", "1.7.0-alpha1", "1.7.0-beta2"} {
+		possibleVersions[raw] = &rjson.ProductVersion{
+			Version: version.Must(version.NewVersion(raw)),
+		}
+	}
+
+	testCases := map[string]struct {
+		lv              LatestVersion
+		pvs             rjson.ProductVersionsMap
+		expectedVersion string
+	}{
+		"highest": {
+			lv:              LatestVersion{Selection: SelectHighest},
+			expectedVersion: "1.6.2",
+		},
+		"lowest": {
+			lv:              LatestVersion{Selection: SelectLowest},
+			expectedVersion: "1.5.0",
+		},
+		"lowest-constrained": {
+			lv: LatestVersion{
+				Selection:   SelectLowest,
+				Constraints: version.MustConstraints(version.NewConstraint(">= 1.6.0")),
+			},
+			expectedVersion: "1.6.0",
+		},
+		"latest-patch": {
+			lv: LatestVersion{
+				Selection:      SelectLatestPatch,
+				CurrentVersion: version.Must(version.NewVersion("1.5.2")),
+			},
+			expectedVersion: "1.5.7",
+		},
+		"previous-minor": {
+			lv:              LatestVersion{Selection: SelectPreviousMinor},
+			expectedVersion: "1.5.7",
+		},
+		"newer-prerelease": {
+			lv:              LatestVersion{Selection: SelectNewerPrerelease},
+			expectedVersion: "1.7.0-beta2",
+		},
+		"newer-prerelease-superseded": {
+			lv: LatestVersion{Selection: SelectNewerPrerelease},
+			pvs: rjson.ProductVersionsMap{
+				"1.6.0-rc1": &rjson.ProductVersion{Version: version.Must(version.NewVersion("1.6.0-rc1"))},
+				"1.6.0":     &rjson.ProductVersion{Version: version.Must(version.NewVersion("1.6.0"))},
+			},
+			expectedVersion: "1.6.0",
+		},
+	}
+
+	for name, testCase := range testCases {
+		name, testCase := name, testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pvs := testCase.pvs
+			if pvs == nil {
+				pvs = possibleVersions
+			}
+
+			selected, ok := testCase.lv.findLatestMatchingVersion(pvs, testCase.lv.Constraints)
+			if !ok {
+				t.Fatalf("expected version %s, got none", testCase.expectedVersion)
+			}
+			if selected.Version.Original() != testCase.expectedVersion {
+				t.Fatalf("expected version %s, got %s", testCase.expectedVersion, selected.Version.Original())
+			}
+		})
+	}
+}
+
+func TestLatestVersion_SelectionNoMatch(t *testing.T) {
+	t.Parallel()
+
+	pvs := rjson.ProductVersionsMap{
+		"1.6.0": &rjson.ProductVersion{Version: version.Must(version.NewVersion("1.6.0"))},
+	}
+	lv := LatestVersion{
+		Selection:      SelectLatestPatch,
+		CurrentVersion: version.Must(version.NewVersion("1.5.0")),
+	}
+	if v, ok := lv.findLatestMatchingVersion(pvs, nil); ok {
+		t.Fatalf("expected no version, got %s", v.Version)
+	}
+
+	lv = LatestVersion{Selection: SelectPreviousMinor}
+	if v, ok := lv.findLatestMatchingVersion(pvs, nil); ok {
+		t.Fatalf("expected no version, got %s", v.Version)
+	}
+}
+
+func TestLatestVersion_ReleaseTime(t *testing.T) {
+	t.Parallel()
+
+	releasedAt := func(raw string, ts time.Time) *rjson.ProductVersion {
+		return &rjson.ProductVersion{
+			Version:          version.Must(version.NewVersion(raw)),
+			TimestampCreated: &ts,
+		}
+	}
+	now := time.Now()
+	possibleVersions := rjson.ProductVersionsMap{
+		"1.5.0": releasedAt("1.5.0", now.Add(-90*24*time.Hour)),
+		"1.6.0": releasedAt("1.6.0", now.Add(-30*24*time.Hour)),
+		"1.6.1": releasedAt("1.6.1", now.Add(-2*24*time.Hour)),
+		"1.6.2": &rjson.ProductVersion{
+			Version: version.Must(version.NewVersion("1.6.2")),
+		},
+	}
+
+	testCases := map[string]struct {
+		lv              LatestVersion
+		expectedVersion string
+	}{
+		"no-filter": {
+			lv:              LatestVersion{},
+			expectedVersion: "1.6.2",
+		},
+		"minimum-age": {
+			lv:              LatestVersion{MinimumAge: 7 * 24 * time.Hour},
+			expectedVersion: "1.6.0",
+		},
+		"as-of": {
+			lv:              LatestVersion{AsOf: now.Add(-60 * 24 * time.Hour)},
+			expectedVersion: "1.5.0",
+		},
+		"as-of-with-minimum-age": {
+			lv: LatestVersion{
+				AsOf:       now.Add(-24 * time.Hour),
+				MinimumAge: 7 * 24 * time.Hour,
+			},
+			expectedVersion: "1.6.0",
+		},
+	}
+
+	for name, testCase := range testCases {
+		name, testCase := name, testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			latest, ok := testCase.lv.findLatestMatchingVersion(possibleVersions, nil)
+			if !ok {
+				t.Fatalf("expected version %s, got none", testCase.expectedVersion)
+			}
+			if latest.Version.Original() != testCase.expectedVersion {
+				t.Fatalf("expected version %s, got %s", testCase.expectedVersion, latest.Version.Original())
+			}
+		})
+	}
+}
+
+func TestParseSelection(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []Selection{SelectHighest, SelectLowest, SelectLatestPatch, SelectPreviousMinor, SelectNewerPrerelease} {
+		parsed, err := ParseSelection(s.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed != s {
+			t.Fatalf("expected %s, got %s", s, parsed)
+		}
+	}
+
+	if _, err := ParseSelection("newest"); err == nil {
+		t.Fatal("expected error for unknown selection")
+	}
+}
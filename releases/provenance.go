@@ -0,0 +1,37 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releases
+
+import (
+	"context"
+	"log"
+
+	rjson "github.com/chushi-io/lf-install/internal/releasesjson"
+	"github.com/chushi-io/lf-install/provenance"
+)
+
+// provenanceVerifier loads attestations from the policy file and returns
+// a function verifying provenance of the downloaded archive,
+// or nil if there is no policy
+func provenanceVerifier(logger *log.Logger, policy *provenance.Policy,
+	pv *rjson.ProductVersion) (func(context.Context, string, rjson.HashSum) error, error) {
+	if policy == nil {
+		return nil, nil
+	}
+
+	envelopes, err := provenance.LoadAttestationsFile(policy.File)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, filename string, sum rjson.HashSum) error {
+		prov, err := policy.Verify(envelopes, filename, sum, pv.Version)
+		if err != nil {
+			return err
+		}
+		logger.Printf("verified provenance of %s: built by %s from %s@%s",
+			filename, prov.BuilderID, prov.Repository, prov.Ref)
+		return nil
+	}, nil
+}
@@ -0,0 +1,105 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releases
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	lferrors "github.com/chushi-io/lf-install/errors"
+	"github.com/chushi-io/lf-install/internal/testutil"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/chushi-io/lf-install/provenance"
+	"github.com/hashicorp/go-version"
+)
+
+// provenanceMockApiRoot serves tofu 1.6.2 archives whose provenance
+// is attested in the provenance package testdata
+var provenanceMockApiRoot = filepath.Join("testdata", "mock_api_tofu_provenance")
+
+const provenanceAttestationsPath = "../provenance/testdata/tofu_1.6.2.intoto.jsonl"
+
+func TestExactVersion_Install_provenance(t *testing.T) {
+	t.Parallel()
+
+	keys, err := provenance.LoadPublicKeysFile("../provenance/testdata/key.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		repository  string
+		expectedErr bool
+	}{
+		"trusted-source": {
+			repository: "github.com/opentofu/opentofu",
+		},
+		"untrusted-source": {
+			repository:  "github.com/example/fork",
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			installDir := t.TempDir()
+			ev := &ExactVersion{
+				Product:                  product.OpenTofu,
+				Version:                  version.Must(version.NewVersion("1.6.2")),
+				InstallDir:               installDir,
+				ApiBaseURL:               testutil.NewTestServer(t, provenanceMockApiRoot).URL,
+				SkipChecksumVerification: true,
+				Provenance: &provenance.Policy{
+					PublicKeys: keys,
+					Repository: tc.repository,
+					Tag:        "v{version}",
+					File:       provenanceAttestationsPath,
+				},
+			}
+			ev.SetLogger(testutil.TestLogger())
+			if err := ev.Validate(); err != nil {
+				t.Fatal(err)
+			}
+
+			execPath, err := ev.Install(context.Background())
+			t.Cleanup(func() { ev.Remove(context.Background()) })
+
+			if tc.expectedErr {
+				if !errors.Is(err, lferrors.ErrProvenanceInvalid) {
+					t.Fatalf("expected provenance error, got %v", err)
+				}
+				if _, err := os.Stat(filepath.Join(installDir, product.OpenTofu.BinaryName())); !os.IsNotExist(err) {
+					t.Fatalf("expected archive not to be unpacked, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(execPath); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestExactVersion_Validate_provenanceFile(t *testing.T) {
+	t.Parallel()
+
+	// attestations are not downloaded, so they must be provided
+	ev := &ExactVersion{
+		Product:    product.OpenTofu,
+		Version:    version.Must(version.NewVersion("1.6.2")),
+		Provenance: &provenance.Policy{AllowUnsigned: true},
+	}
+	if err := ev.Validate(); err == nil {
+		t.Fatal("expected error for provenance policy without attestations file")
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releases
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chushi-io/lf-install/errors"
+	rjson "github.com/chushi-io/lf-install/internal/releasesjson"
+)
+
+// releaseCutoff returns the latest release time acceptable given
+// the minimum age of a release and the point in time to resolve
+// versions as of (now if zero), or false if neither is set
+func releaseCutoff(minimumAge time.Duration, asOf time.Time) (time.Time, bool) {
+	if minimumAge <= 0 && asOf.IsZero() {
+		return time.Time{}, false
+	}
+	cutoff := asOf
+	if cutoff.IsZero() {
+		cutoff = time.Now()
+	}
+	return cutoff.Add(-minimumAge), true
+}
+
+// releasedBy reports whether the version was released by cutoff.
+// Versions without known release time are never considered released,
+// since their age cannot be proven.
+func releasedBy(pv *rjson.ProductVersion, cutoff time.Time) bool {
+	return pv.TimestampCreated != nil && !pv.TimestampCreated.After(cutoff)
+}
+
+// checkReleaseTimesKnown returns errors.ErrReleaseTimeUnavailable if none
+// of the versions record their release time (e.g. as is the case with
+// standard index.json files), since every version would be excluded
+func checkReleaseTimesKnown(productName string, pvs rjson.ProductVersionsMap) error {
+	for _, pv := range pvs {
+		if pv.TimestampCreated != nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no version of %s records timestamp_created, "+
+		"which is required to apply MinimumAge or AsOf", errors.ErrReleaseTimeUnavailable, productName)
+}
+
+func validateMinimumAge(minimumAge time.Duration) error {
+	if minimumAge < 0 {
+		return fmt.Errorf("MinimumAge must not be negative")
+	}
+	return nil
+}
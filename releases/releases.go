@@ -13,4 +13,6 @@ var (
 	defaultInstallTimeout = 30 * time.Second
 	defaultListTimeout    = 10 * time.Second
 	discardLogger         = log.New(io.Discard, "", 0)
+
+	defaultVerifyVersionTimeout = 10 * time.Second
 )
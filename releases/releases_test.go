@@ -20,9 +20,15 @@ import (
 var (
 	_ src.Installable = &ExactVersion{}
 	_ src.Removable   = &ExactVersion{}
+	_ src.Describable = &ExactVersion{}
+
+	_ src.PostDownloadHookSettable = &ExactVersion{}
 
 	_ src.Installable = &LatestVersion{}
 	_ src.Removable   = &LatestVersion{}
+	_ src.Describable = &LatestVersion{}
+
+	_ src.PostDownloadHookSettable = &LatestVersion{}
 )
 
 func TestLatestVersion(t *testing.T) {
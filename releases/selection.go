@@ -0,0 +1,136 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releases
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-version"
+)
+
+// Selection determines which of the versions
+// matching constraints is picked for installation
+type Selection int
+
+const (
+	// SelectHighest picks the highest version
+	SelectHighest Selection = iota
+
+	// SelectLowest picks the lowest version,
+	// e.g. for compatibility testing
+	SelectLowest
+
+	// SelectLatestPatch picks the highest patch version
+	// of the minor version of CurrentVersion
+	SelectLatestPatch
+
+	// SelectPreviousMinor picks the highest version of the minor version
+	// preceding the highest stable one (i.e. N-1)
+	SelectPreviousMinor
+
+	// SelectNewerPrerelease picks the highest prerelease if it's newer
+	// than the highest stable version, or the highest stable version otherwise
+	SelectNewerPrerelease
+)
+
+var selectionNames = map[Selection]string{
+	SelectHighest:         "highest",
+	SelectLowest:          "lowest",
+	SelectLatestPatch:     "latest-patch",
+	SelectPreviousMinor:   "previous-minor",
+	SelectNewerPrerelease: "newer-prerelease",
+}
+
+func (s Selection) String() string {
+	if name, ok := selectionNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Selection(%d)", int(s))
+}
+
+// ParseSelection parses name of a selection strategy (e.g. "latest-patch")
+func ParseSelection(name string) (Selection, error) {
+	for s, n := range selectionNames {
+		if n == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown selection strategy %q", name)
+}
+
+// selectVersion picks a version from the given versions per the strategy.
+// Prereleases are only considered by SelectNewerPrerelease or when
+// includePrereleases is true.
+func selectVersion(versions version.Collection, s Selection, current *version.Version, includePrereleases bool) (*version.Version, bool) {
+	sorted := make(version.Collection, 0, len(versions))
+	for _, v := range versions {
+		if v.Prerelease() != "" && !includePrereleases && s != SelectNewerPrerelease {
+			continue
+		}
+		sorted = append(sorted, v)
+	}
+	if len(sorted) == 0 {
+		return nil, false
+	}
+	sort.Stable(sorted)
+
+	switch s {
+	case SelectHighest:
+		return sorted[len(sorted)-1], true
+	case SelectLowest:
+		return sorted[0], true
+	case SelectLatestPatch:
+		if current == nil {
+			return nil, false
+		}
+		return highestMatching(sorted, func(v *version.Version) bool {
+			return compareMinor(v, current) == 0
+		})
+	case SelectPreviousMinor:
+		latestStable, ok := highestMatching(sorted, isStable)
+		if !ok {
+			return nil, false
+		}
+		return highestMatching(sorted, func(v *version.Version) bool {
+			return compareMinor(v, latestStable) < 0
+		})
+	case SelectNewerPrerelease:
+		latest := sorted[len(sorted)-1]
+		if latest.Prerelease() != "" {
+			return latest, true
+		}
+		return highestMatching(sorted, isStable)
+	}
+
+	return nil, false
+}
+
+// highestMatching returns the highest of sorted versions matching f
+func highestMatching(sorted version.Collection, f func(v *version.Version) bool) (*version.Version, bool) {
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if f(sorted[i]) {
+			return sorted[i], true
+		}
+	}
+	return nil, false
+}
+
+func isStable(v *version.Version) bool {
+	return v.Prerelease() == ""
+}
+
+// compareMinor compares major and minor segments of versions
+func compareMinor(a, b *version.Version) int {
+	as, bs := a.Segments64(), b.Segments64()
+	for i := 0; i < 2; i++ {
+		switch {
+		case as[i] < bs[i]:
+			return -1
+		case as[i] > bs[i]:
+			return 1
+		}
+	}
+	return 0
+}
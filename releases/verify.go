@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releases
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/chushi-io/lf-install/errors"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/hashicorp/go-version"
+)
+
+// verifyInstalledVersion executes the installed binary via GetVersion
+// of the product and checks that it reports the expected version
+func verifyInstalledVersion(ctx context.Context, logger *log.Logger, p product.Product,
+	execPath string, expected *version.Version, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = defaultVerifyVersionTimeout
+	}
+	ctx, cancelFunc := context.WithTimeout(ctx, timeout)
+	defer cancelFunc()
+
+	logger.Printf("verifying version of %s", execPath)
+	actual, err := p.GetVersion(ctx, execPath)
+	if err != nil {
+		return fmt.Errorf("unable to verify version of %s: %w", execPath, err)
+	}
+
+	// build metadata (e.g. +ent) is ignored when comparing
+	if !actual.Equal(expected) {
+		return &errors.VersionMismatchError{
+			Product:  p.Name,
+			ExecPath: execPath,
+			Expected: expected.String(),
+			Actual:   actual.String(),
+		}
+	}
+	logger.Printf("verified %s reports version %s", execPath, actual)
+
+	return nil
+}
+
+// stagedVersionVerifier returns a function verifying the version of the binary
+// unpacked for execPath before it is moved into place, such that a binary
+// reporting another version never replaces a previously installed one
+func stagedVersionVerifier(logger *log.Logger, p product.Product, execPath string,
+	expected *version.Version, timeout time.Duration) func(context.Context, map[string]string) error {
+	return func(ctx context.Context, stagedPaths map[string]string) error {
+		stagedPath, ok := stagedPaths[execPath]
+		if !ok {
+			return fmt.Errorf("unable to verify version: %s not found in the archive", p.BinaryName())
+		}
+		if err := os.Chmod(stagedPath, 0o700); err != nil {
+			return err
+		}
+
+		err := verifyInstalledVersion(ctx, logger, p, stagedPath, expected, timeout)
+		var vme *errors.VersionMismatchError
+		if stderrors.As(err, &vme) {
+			vme.ExecPath = execPath
+		}
+		return err
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releases
+
+import (
+	"context"
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chushi-io/lf-install/errors"
+	"github.com/chushi-io/lf-install/internal/testutil"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/hashicorp/go-version"
+)
+
+func TestVerifyInstalledVersion(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		reported    string
+		expected    string
+		expectedErr error
+	}{
+		"matching": {
+			reported: "1.8.0",
+			expected: "1.8.0",
+		},
+		"matching-ignoring-metadata": {
+			reported: "1.8.0",
+			expected: "1.8.0+ent",
+		},
+		"mismatching": {
+			reported:    "1.7.0",
+			expected:    "1.8.0",
+			expectedErr: errors.ErrVersionMismatch,
+		},
+	}
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := product.Product{
+				Name: "tofu",
+				GetVersion: func(ctx context.Context, execPath string) (*version.Version, error) {
+					return version.NewVersion(tc.reported)
+				},
+			}
+
+			err := verifyInstalledVersion(context.Background(), testutil.TestLogger(), p,
+				"/fake/tofu", version.Must(version.NewVersion(tc.expected)), time.Second)
+			if tc.expectedErr == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !stderrors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %q, got %q", tc.expectedErr, err)
+			}
+			var vme *errors.VersionMismatchError
+			if !stderrors.As(err, &vme) || vme.Actual != tc.reported {
+				t.Fatalf("expected mismatch error reporting %s, got %#v", tc.reported, err)
+			}
+		})
+	}
+}
+
+func TestStagedVersionVerifier(t *testing.T) {
+	t.Parallel()
+
+	stagedPath := filepath.Join(t.TempDir(), "tofu")
+	if err := os.WriteFile(stagedPath, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	execPath := "/usr/local/bin/tofu"
+
+	var executed string
+	p := product.Product{
+		Name:       "tofu",
+		BinaryName: func() string { return "tofu" },
+		GetVersion: func(ctx context.Context, path string) (*version.Version, error) {
+			executed = path
+			return version.NewVersion("1.7.0")
+		},
+	}
+
+	verify := stagedVersionVerifier(testutil.TestLogger(), p, execPath,
+		version.Must(version.NewVersion("1.8.0")), time.Second)
+	err := verify(context.Background(), map[string]string{execPath: stagedPath})
+
+	var vme *errors.VersionMismatchError
+	if !stderrors.As(err, &vme) {
+		t.Fatalf("expected mismatch error, got %v", err)
+	}
+	if executed != stagedPath {
+		t.Fatalf("expected staged binary to be executed, given %q", executed)
+	}
+	if vme.ExecPath != execPath {
+		t.Fatalf("expected mismatch to be reported for %q, given %q", execPath, vme.ExecPath)
+	}
+
+	err = verify(context.Background(), map[string]string{})
+	if err == nil {
+		t.Fatal("expected error for binary missing in the archive")
+	}
+}
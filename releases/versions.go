@@ -25,6 +25,24 @@ type Versions struct {
 
 	ListTimeout time.Duration
 
+	// MinimumAge excludes versions released less than MinimumAge ago
+	// (or before AsOf, if set)
+	MinimumAge time.Duration
+
+	// AsOf excludes versions released after the given time
+	//
+	// Versions without known release time are excluded
+	// when either MinimumAge or AsOf is set, and errors.ErrReleaseTimeUnavailable
+	// is returned if the releases API doesn't record release time of any
+	// version (which standard index.json files don't, unlike GitHub releases).
+	AsOf time.Time
+
+	// YankPolicy determines where to look for yanked versions, which
+	// are listed, but reported via ExactVersion.Yanked, unless ExcludeYanked
+	// is set. The policy also applies to installation of listed versions.
+	YankPolicy    YankPolicy
+	ExcludeYanked bool
+
 	// Install represents configuration for installation of any listed version
 	Install InstallationOptions
 }
@@ -34,6 +52,11 @@ type InstallationOptions struct {
 	Dir        string
 	LicenseDir string
 
+	// CompletionDir and ManDir represent directory paths where to install
+	// shell completion scripts and man pages (skipped if empty)
+	CompletionDir string
+	ManDir        string
+
 	SkipChecksumVerification bool
 
 	// ArmoredPublicKey is a public PGP key in ASCII/armor format to use
@@ -51,6 +74,10 @@ func (v *Versions) List(ctx context.Context) ([]src.Source, error) {
 		return nil, err
 	}
 
+	if err := validateMinimumAge(v.MinimumAge); err != nil {
+		return nil, err
+	}
+
 	timeout := defaultListTimeout
 	if v.ListTimeout > 0 {
 		timeout = v.ListTimeout
@@ -59,15 +86,31 @@ func (v *Versions) List(ctx context.Context) ([]src.Source, error) {
 	defer cancelFunc()
 
 	r := rjson.NewReleases()
+	if v.Product.ReleasesBaseURL != "" {
+		r.BaseURL = v.Product.ReleasesBaseURL
+	}
+	r.Backend = v.Product.ReleasesBackend
 	pvs, err := r.ListProductVersions(ctx, v.Product.Name)
 	if err != nil {
 		return nil, err
 	}
 
+	yanked, err := v.YankPolicy.yankedVersions(ctx, r, v.Product.Name)
+	if err != nil {
+		return nil, err
+	}
+	markYanked(pvs, yanked)
+
 	versions := pvs.AsSlice()
 	sort.Stable(versions)
 
 	expectedMetadata := enterpriseVersionMetadata(v.Enterprise)
+	cutoff, checkReleaseTime := releaseCutoff(v.MinimumAge, v.AsOf)
+	if checkReleaseTime {
+		if err := checkReleaseTimesKnown(v.Product.Name, pvs); err != nil {
+			return nil, err
+		}
+	}
 
 	installables := make([]src.Source, 0)
 	for _, pv := range versions {
@@ -81,6 +124,16 @@ func (v *Versions) List(ctx context.Context) ([]src.Source, error) {
 			continue
 		}
 
+		if checkReleaseTime && !releasedBy(pv, cutoff) {
+			// skip version which wasn't released by the cutoff
+			continue
+		}
+
+		if pv.Yanked && v.ExcludeYanked {
+			// skip version which was yanked
+			continue
+		}
+
 		ev := &ExactVersion{
 			Product:    v.Product,
 			Version:    pv.Version,
@@ -88,8 +141,15 @@ func (v *Versions) List(ctx context.Context) ([]src.Source, error) {
 			Timeout:    v.Install.Timeout,
 			LicenseDir: v.Install.LicenseDir,
 
+			CompletionDir: v.Install.CompletionDir,
+			ManDir:        v.Install.ManDir,
+
 			ArmoredPublicKey:         v.Install.ArmoredPublicKey,
 			SkipChecksumVerification: v.Install.SkipChecksumVerification,
+
+			YankPolicy:   v.YankPolicy,
+			yanked:       pv.Yanked,
+			yankedReason: pv.YankedReason,
 		}
 
 		if v.Enterprise != nil {
@@ -5,8 +5,15 @@ package releases
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	lferrors "github.com/chushi-io/lf-install/errors"
 	"github.com/chushi-io/lf-install/internal/testutil"
 	"github.com/chushi-io/lf-install/product"
 	"github.com/chushi-io/lf-install/src"
@@ -14,6 +21,101 @@ import (
 	"github.com/hashicorp/go-version"
 )
 
+func TestVersions_List_releaseTime(t *testing.T) {
+	t.Parallel()
+
+	index := `{
+  "name": "tofu",
+  "versions": {
+    "1.6.0": {"name": "tofu", "version": "1.6.0", "timestamp_created": "2024-01-10T12:00:00Z"},
+    "1.6.1": {"name": "tofu", "version": "1.6.1", "timestamp_created": "2024-01-24T12:00:00Z"},
+    "1.6.2": {"name": "tofu", "version": "1.6.2"}
+  }
+}`
+	indexPath := filepath.Join(t.TempDir(), "index.json")
+	if err := os.WriteFile(indexPath, []byte(index), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/tofu/index.json", testutil.JsonFromFile(indexPath))
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	p := product.OpenTofu
+	p.ReleasesBaseURL = ts.URL
+
+	testCases := map[string]struct {
+		versions         Versions
+		expectedVersions []string
+	}{
+		"no-filter": {
+			versions:         Versions{Product: p},
+			expectedVersions: []string{"1.6.0", "1.6.1", "1.6.2"},
+		},
+		"as-of": {
+			versions: Versions{
+				Product: p,
+				AsOf:    time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC),
+			},
+			expectedVersions: []string{"1.6.0"},
+		},
+		"as-of-with-minimum-age": {
+			versions: Versions{
+				Product:    p,
+				AsOf:       time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC),
+				MinimumAge: 7 * 24 * time.Hour,
+			},
+			expectedVersions: []string{"1.6.0"},
+		},
+		"minimum-age": {
+			versions: Versions{
+				Product:    p,
+				MinimumAge: 24 * time.Hour,
+			},
+			expectedVersions: []string{"1.6.0", "1.6.1"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		name, testCase := name, testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			sources, err := testCase.versions.List(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(testCase.expectedVersions, sourcesToRawVersions(sources)); diff != "" {
+				t.Fatalf("unexpected versions: %s", diff)
+			}
+		})
+	}
+}
+
+func TestVersions_List_releaseTimeUnavailable(t *testing.T) {
+	t.Parallel()
+
+	// the mock index doesn't record timestamp_created of any version
+	mockApiRoot := filepath.Join("testdata", "mock_api_tf_0_14_with_prereleases")
+	p := product.OpenTofu
+	p.Name = "terraform"
+	p.ReleasesBaseURL = testutil.NewTestServer(t, mockApiRoot).URL
+
+	_, err := (&Versions{Product: p, MinimumAge: 24 * time.Hour}).List(context.Background())
+	if !errors.Is(err, lferrors.ErrReleaseTimeUnavailable) {
+		t.Fatalf("expected ErrReleaseTimeUnavailable from Versions, given %#v", err)
+	}
+
+	lv := &LatestVersion{Product: p, AsOf: time.Now()}
+	lv.SetLogger(testutil.TestLogger())
+	_, err = lv.Install(context.Background())
+	t.Cleanup(func() { lv.Remove(context.Background()) })
+	if !errors.Is(err, lferrors.ErrReleaseTimeUnavailable) {
+		t.Fatalf("expected ErrReleaseTimeUnavailable from LatestVersion, given %#v", err)
+	}
+}
+
 func TestVersions_List(t *testing.T) {
 	testutil.EndToEndTest(t)
 
@@ -0,0 +1,91 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/chushi-io/lf-install/errors"
+	rjson "github.com/chushi-io/lf-install/internal/releasesjson"
+)
+
+// YankPolicy determines where to look for versions which were yanked
+// (withdrawn) upstream and how ExactVersion treats them.
+//
+// Versions marked as "yanked" in the index are always honored.
+// Additional sources use the following format:
+//
+//	{"versions": {"1.6.1": "regression in state locking"}}
+type YankPolicy struct {
+	// CheckMirror enables lookup of yanked.json
+	// published next to index.json of the product
+	CheckMirror bool
+
+	// File represents path to a local file listing yanked versions
+	File string
+
+	// RefuseExact makes ExactVersion fail with errors.VersionYankedError
+	// when the version is yanked, instead of logging a warning
+	RefuseExact bool
+}
+
+// yankedVersions collects versions yanked in the mirror and the local file
+func (yp YankPolicy) yankedVersions(ctx context.Context, rels *rjson.Releases, productName string) (rjson.YankedVersions, error) {
+	yanked := rjson.YankedVersions{}
+
+	if yp.CheckMirror {
+		mirrorYanked, err := rels.GetYankedVersions(ctx, productName)
+		if err != nil {
+			return nil, err
+		}
+		yanked.Merge(mirrorYanked)
+	}
+
+	if yp.File != "" {
+		f, err := os.Open(yp.File)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		fileYanked, err := rjson.ParseYankedVersions(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", yp.File, err)
+		}
+		yanked.Merge(fileYanked)
+	}
+
+	return yanked, nil
+}
+
+// markYanked marks versions which were yanked as such
+func markYanked(pvs rjson.ProductVersionsMap, yanked rjson.YankedVersions) {
+	for _, pv := range pvs {
+		if reason, ok := yanked.Lookup(pv); ok {
+			pv.Yanked = true
+			pv.YankedReason = reason
+		}
+	}
+}
+
+// checkYanked logs a warning if the version was yanked,
+// or returns an error if the policy refuses it
+func (yp YankPolicy) checkYanked(logger *log.Logger, productName string, pv *rjson.ProductVersion) error {
+	if !pv.Yanked {
+		return nil
+	}
+	if yp.RefuseExact {
+		return &errors.VersionYankedError{
+			Product: productName,
+			Version: pv.Version.String(),
+			Reason:  pv.YankedReason,
+		}
+	}
+	logger.Printf("warning: installing %s %s which was yanked (reason: %q)",
+		productName, pv.Version, pv.YankedReason)
+	return nil
+}
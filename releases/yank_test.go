@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package releases
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	lferrors "github.com/chushi-io/lf-install/errors"
+	rjson "github.com/chushi-io/lf-install/internal/releasesjson"
+	"github.com/chushi-io/lf-install/internal/testutil"
+	"github.com/chushi-io/lf-install/product"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-version"
+)
+
+// yankMockApiRoot mocks a releases API with 1.6.1 marked as yanked
+// in the index and 1.6.2 listed in yanked.json
+var yankMockApiRoot = filepath.Join("testdata", "mock_api_tofu_yanked")
+
+func TestVersions_List_yanked(t *testing.T) {
+	t.Parallel()
+
+	p := product.OpenTofu
+	p.ReleasesBaseURL = testutil.NewTestServer(t, yankMockApiRoot).URL
+
+	v := &Versions{
+		Product:    p,
+		YankPolicy: YankPolicy{CheckMirror: true},
+	}
+	sources, err := v.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	yanked := make(map[string]string, 0)
+	for _, s := range sources {
+		ev := s.(*ExactVersion)
+		if reason, ok := ev.Yanked(); ok {
+			yanked[ev.Version.String()] = reason
+		}
+	}
+	expectedYanked := map[string]string{
+		"1.6.1": "regression",
+		"1.6.2": "broken build",
+	}
+	if diff := cmp.Diff(expectedYanked, yanked); diff != "" {
+		t.Fatalf("unexpected yanked versions: %s", diff)
+	}
+
+	v.ExcludeYanked = true
+	sources, err = v.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"1.6.0"}, sourcesToRawVersions(sources)); diff != "" {
+		t.Fatalf("unexpected versions: %s", diff)
+	}
+}
+
+func TestLatestVersion_skipsYanked(t *testing.T) {
+	t.Parallel()
+
+	yankedFile := filepath.Join(t.TempDir(), "yanked.json")
+	err := os.WriteFile(yankedFile, []byte(`{"versions": {"1.6.2": ""}}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pvs := rjson.ProductVersionsMap{}
+	for _, raw := range []string{"1.6.0", "1.6.1", "1.6.2"} {
+		pvs[raw] = &rjson.ProductVersion{Version: version.Must(version.NewVersion(raw))}
+	}
+	pvs["1.6.1"].Yanked = true
+
+	lv := &LatestVersion{YankPolicy: YankPolicy{File: yankedFile}}
+	yanked, err := lv.YankPolicy.yankedVersions(context.Background(), rjson.NewReleases(), "tofu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	markYanked(pvs, yanked)
+
+	latest, ok := lv.findLatestMatchingVersion(pvs, nil)
+	if !ok {
+		t.Fatal("expected version to be found")
+	}
+	if latest.Version.String() != "1.6.0" {
+		t.Fatalf("expected 1.6.0, got %s", latest.Version)
+	}
+}
+
+func TestExactVersion_refusesYanked(t *testing.T) {
+	t.Parallel()
+
+	ev := &ExactVersion{
+		Product:    product.OpenTofu,
+		Version:    version.Must(version.NewVersion("1.6.2")),
+		InstallDir: t.TempDir(),
+		ApiBaseURL: testutil.NewTestServer(t, yankMockApiRoot).URL,
+		YankPolicy: YankPolicy{CheckMirror: true, RefuseExact: true},
+	}
+	ev.SetLogger(testutil.TestLogger())
+
+	_, err := ev.Install(context.Background())
+	if !errors.Is(err, lferrors.ErrVersionYanked) {
+		t.Fatalf("expected yanked version error, got %v", err)
+	}
+	if reason, ok := ev.Yanked(); !ok || reason != "broken build" {
+		t.Fatalf("expected version to be reported as yanked, got (%q, %t)", reason, ok)
+	}
+}
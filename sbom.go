@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package install
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chushi-io/lf-install/sbom"
+	"github.com/chushi-io/lf-install/src"
+)
+
+var _ PostInstallHook = &SBOMWriter{}
+
+// SBOMWriter is a hook which writes a software bill of materials
+// describing the product found, installed or built, including
+// the archive it was downloaded from (if any), license files
+// and Go modules compiled into the binary.
+//
+// Failing to write the document fails the installation.
+// The document is not removed along with the installation.
+type SBOMWriter struct {
+	Format sbom.Format
+
+	// Path represents where to write the document (defaults to path
+	// of the executable with extension of the format appended,
+	// e.g. tofu.spdx.json, but only for sources which created files,
+	// so that documents aren't written next to binaries found elsewhere)
+	Path string
+}
+
+func (w *SBOMWriter) PostInstall(ctx context.Context, event HookEvent) error {
+	s := sbom.Subject{
+		Product:  event.Product,
+		Version:  event.Version,
+		ExecPath: event.ExecPath,
+		Files:    event.Paths,
+	}
+	if ds, ok := event.Source.(src.Describable); ok {
+		s.Download = ds.Details().Download
+	}
+
+	path := w.Path
+	if path == "" {
+		if len(event.Paths) == 0 {
+			return fmt.Errorf("path to the document is required for %s, "+
+				"which wasn't installed by the source", event.ExecPath)
+		}
+		path = event.ExecPath + w.Format.Extension()
+	}
+	return sbom.WriteFile(path, w.Format, s)
+}
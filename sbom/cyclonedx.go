@@ -0,0 +1,147 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	lfversion "github.com/chushi-io/lf-install/version"
+)
+
+type cdxBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components,omitempty"`
+	Dependencies []cdxDependency `json:"dependencies,omitempty"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type               string                 `json:"type"`
+	BOMRef             string                 `json:"bom-ref,omitempty"`
+	Name               string                 `json:"name"`
+	Version            string                 `json:"version,omitempty"`
+	PURL               string                 `json:"purl,omitempty"`
+	Hashes             []cdxHash              `json:"hashes,omitempty"`
+	ExternalReferences []cdxExternalReference `json:"externalReferences,omitempty"`
+	Properties         []cdxProperty          `json:"properties,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxExternalReference struct {
+	Type    string    `json:"type"`
+	URL     string    `json:"url"`
+	Comment string    `json:"comment,omitempty"`
+	Hashes  []cdxHash `json:"hashes,omitempty"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+func writeCycloneDX(w io.Writer, inv *inventory) error {
+	id, err := newUUID()
+	if err != nil {
+		return err
+	}
+
+	productRef := inv.purl()
+	if productRef == "" {
+		productRef = "product:" + inv.Product
+		if v := inv.version(); v != "" {
+			productRef += "@" + v
+		}
+	}
+	product := cdxComponent{
+		Type:    "application",
+		BOMRef:  productRef,
+		Name:    inv.Product,
+		Version: inv.version(),
+		PURL:    inv.purl(),
+		Hashes:  []cdxHash{{Alg: "SHA-256", Content: inv.binary.sha256}},
+	}
+	if d := inv.Download; d != nil {
+		ref := cdxExternalReference{
+			Type:    "distribution",
+			URL:     d.URL,
+			Comment: d.Filename,
+		}
+		if d.SHA256 != "" {
+			ref.Hashes = []cdxHash{{Alg: "SHA-256", Content: d.SHA256}}
+		}
+		product.ExternalReferences = []cdxExternalReference{ref}
+	}
+	if inv.goVersion != "" {
+		product.Properties = []cdxProperty{{Name: "lf-install:go-version", Value: inv.goVersion}}
+	}
+
+	bom := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + id,
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: inv.Created.UTC().Format(time.RFC3339),
+			Tools: cdxTools{Components: []cdxComponent{{
+				Type:    "application",
+				Name:    "lf-install",
+				Version: lfversion.Version().String(),
+			}}},
+			Component: product,
+		},
+	}
+
+	for _, l := range inv.licenses {
+		bom.Components = append(bom.Components, cdxComponent{
+			Type:   "file",
+			BOMRef: "file:" + l.name,
+			Name:   l.name,
+			Hashes: []cdxHash{{Alg: "SHA-256", Content: l.sha256}},
+		})
+	}
+
+	dependsOn := make([]string, 0, len(inv.modules))
+	for _, m := range inv.modules {
+		purl := goPURL(m.path, m.version)
+		bom.Components = append(bom.Components, cdxComponent{
+			Type:    "library",
+			BOMRef:  purl,
+			Name:    m.path,
+			Version: m.version,
+			PURL:    purl,
+		})
+		dependsOn = append(dependsOn, purl)
+	}
+	if len(dependsOn) > 0 {
+		bom.Dependencies = []cdxDependency{{Ref: productRef, DependsOn: dependsOn}}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bom)
+}
@@ -0,0 +1,265 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package sbom generates software bills of materials (SPDX or CycloneDX)
+// describing installed products, including Go modules compiled
+// into their binaries.
+package sbom
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"debug/buildinfo"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chushi-io/lf-install/src"
+	"github.com/hashicorp/go-version"
+)
+
+// Format represents the format of the generated document
+type Format int
+
+const (
+	// SPDX represents SPDX 2.3 in JSON
+	SPDX Format = iota
+
+	// CycloneDX represents CycloneDX 1.5 in JSON
+	CycloneDX
+)
+
+func (f Format) String() string {
+	switch f {
+	case SPDX:
+		return "spdx"
+	case CycloneDX:
+		return "cyclonedx"
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// Extension returns the conventional file extension of the format
+func (f Format) Extension() string {
+	if f == CycloneDX {
+		return ".cdx.json"
+	}
+	return ".spdx.json"
+}
+
+// ParseFormat parses name of a format (spdx or cyclonedx)
+func ParseFormat(name string) (Format, error) {
+	for _, f := range []Format{SPDX, CycloneDX} {
+		if f.String() == name {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown SBOM format %q", name)
+}
+
+// Subject describes the installed product to generate a document for
+type Subject struct {
+	// Product represents name of the product
+	// (defaults to name of the executable)
+	Product string
+
+	// Version represents the installed version (optional)
+	Version *version.Version
+
+	// ExecPath represents path to the installed binary, which is hashed
+	// and whose Go build information (if any) lists compiled modules
+	ExecPath string
+
+	// Files represents paths of files created by the installation,
+	// among which license files are recognized by name
+	Files []string
+
+	// Download describes the archive the product was installed from (optional)
+	Download *src.Download
+
+	// Created represents the creation time of the document (now by default)
+	Created time.Time
+}
+
+// Generate writes the document describing s in the given format
+func Generate(w io.Writer, f Format, s Subject) error {
+	inv, err := collect(s)
+	if err != nil {
+		return err
+	}
+
+	switch f {
+	case SPDX:
+		return writeSPDX(w, inv)
+	case CycloneDX:
+		return writeCycloneDX(w, inv)
+	}
+	return fmt.Errorf("unknown SBOM format: %s", f)
+}
+
+// WriteFile writes the document describing s into the file at path
+func WriteFile(path string, f Format, s Subject) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = Generate(out, f, s)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("unable to write SBOM to %s: %w", path, err)
+	}
+	return nil
+}
+
+// inventory represents everything known about the subject
+type inventory struct {
+	Subject
+
+	binary   file
+	licenses []file
+
+	// mainModule and goVersion are empty for binaries
+	// without Go build information
+	mainModule string
+	goVersion  string
+	modules    []goModule
+}
+
+type file struct {
+	name   string
+	sha1   string
+	sha256 string
+}
+
+type goModule struct {
+	path    string
+	version string
+}
+
+func collect(s Subject) (*inventory, error) {
+	if s.ExecPath == "" {
+		return nil, fmt.Errorf("unknown path to the executable")
+	}
+	if s.Product == "" {
+		s.Product = strings.TrimSuffix(filepath.Base(s.ExecPath), ".exe")
+	}
+	if s.Created.IsZero() {
+		s.Created = time.Now()
+	}
+
+	binary, err := hashFile(s.ExecPath)
+	if err != nil {
+		return nil, err
+	}
+	inv := &inventory{
+		Subject: s,
+		binary:  binary,
+	}
+
+	for _, path := range s.Files {
+		if !isLicenseFile(filepath.Base(path)) {
+			continue
+		}
+		fi, err := os.Stat(path)
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		license, err := hashFile(path)
+		if err != nil {
+			return nil, err
+		}
+		inv.licenses = append(inv.licenses, license)
+	}
+
+	// binaries not built with Go simply have no modules to list
+	info, err := buildinfo.ReadFile(s.ExecPath)
+	if err == nil {
+		inv.mainModule = info.Main.Path
+		inv.goVersion = info.GoVersion
+		for _, dep := range info.Deps {
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			inv.modules = append(inv.modules, goModule{path: dep.Path, version: dep.Version})
+		}
+	}
+
+	return inv, nil
+}
+
+// version returns the version of the product, if known
+func (inv *inventory) version() string {
+	if inv.Version == nil {
+		return ""
+	}
+	return inv.Version.String()
+}
+
+// purl returns the package URL of the product, which is only known
+// for Go binaries (e.g. pkg:golang/github.com/opentofu/opentofu@v1.6.2)
+func (inv *inventory) purl() string {
+	if inv.mainModule == "" || inv.Version == nil {
+		return ""
+	}
+	return goPURL(inv.mainModule, "v"+inv.version())
+}
+
+func goPURL(path, version string) string {
+	if version == "" {
+		return "pkg:golang/" + path
+	}
+	return "pkg:golang/" + path + "@" + version
+}
+
+// licenseFilePrefixes represents names of license files
+// commonly shipped in release archives (e.g. LICENSE.txt)
+var licenseFilePrefixes = []string{"LICENSE", "LICENCE", "COPYING", "NOTICE"}
+
+func isLicenseFile(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, prefix := range licenseFilePrefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hashFile obtains SHA-1 (required by SPDX) and SHA-256 of the file
+func hashFile(path string) (file, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return file{}, err
+	}
+	defer f.Close()
+
+	h1, h256 := sha1.New(), sha256.New()
+	if _, err := io.Copy(io.MultiWriter(h1, h256), f); err != nil {
+		return file{}, fmt.Errorf("unable to hash %s: %w", path, err)
+	}
+	return file{
+		name:   filepath.Base(path),
+		sha1:   hex.EncodeToString(h1.Sum(nil)),
+		sha256: hex.EncodeToString(h256.Sum(nil)),
+	}, nil
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
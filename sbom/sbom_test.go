@@ -0,0 +1,209 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chushi-io/lf-install/src"
+	"github.com/hashicorp/go-version"
+)
+
+// testSubject describes the test binary itself, which is built with Go
+// and therefore records modules it depends on (e.g. go-version)
+func testSubject(t *testing.T) Subject {
+	execPath, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	licensePath := filepath.Join(t.TempDir(), "LICENSE.txt")
+	if err := os.WriteFile(licensePath, []byte("license text"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return Subject{
+		Product:  "tofu",
+		Version:  version.Must(version.NewVersion("1.6.2")),
+		ExecPath: execPath,
+		Files:    []string{execPath, licensePath},
+		Download: &src.Download{
+			URL:      "https://example.com/tofu/1.6.2/tofu_1.6.2_linux_amd64.zip",
+			Filename: "tofu_1.6.2_linux_amd64.zip",
+			SHA256:   "0123456789abcdef",
+		},
+	}
+}
+
+// sha256 of "license text"
+const licenseSHA256 = "086ef1421303f033b3b925a9c783576ff65dc9d44a1aeadbd5fac5e61953ca26"
+
+func TestGenerate_spdx(t *testing.T) {
+	t.Parallel()
+
+	s := testSubject(t)
+	var buf bytes.Buffer
+	if err := Generate(&buf, SPDX, s); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc spdxDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.SPDXVersion != "SPDX-2.3" {
+		t.Fatalf("unexpected SPDX version: %q", doc.SPDXVersion)
+	}
+	if !strings.HasPrefix(doc.DocumentNamespace, "https://spdx.org/spdxdocs/lf-install/tofu-1.6.2-") {
+		t.Fatalf("unexpected namespace: %q", doc.DocumentNamespace)
+	}
+
+	product := doc.Packages[0]
+	if product.Name != "tofu" || product.VersionInfo != "1.6.2" {
+		t.Fatalf("unexpected product: %s %s", product.Name, product.VersionInfo)
+	}
+	if product.DownloadLocation != s.Download.URL {
+		t.Fatalf("unexpected download location: %q", product.DownloadLocation)
+	}
+	if len(product.Checksums) != 1 || product.Checksums[0].ChecksumValue != s.Download.SHA256 {
+		t.Fatalf("unexpected archive checksums: %#v", product.Checksums)
+	}
+	// files contained in the package must be analyzed
+	if !product.FilesAnalyzed || product.VerificationCode == nil {
+		t.Fatalf("expected files to be analyzed with verification code, given %#v", product)
+	}
+
+	binary, err := hashFile(s.ExecPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binarySum := binary.sha256
+	files := make(map[string]string, 0)
+	for _, f := range doc.Files {
+		files[f.FileName] = f.Checksums[0].ChecksumValue
+		if len(f.Checksums) != 2 || f.Checksums[1].Algorithm != "SHA1" {
+			t.Fatalf("expected SHA1 checksum of %s, given %#v", f.FileName, f.Checksums)
+		}
+	}
+	if files["./"+filepath.Base(s.ExecPath)] != binarySum {
+		t.Fatalf("expected binary checksum %s, files: %#v", binarySum, files)
+	}
+	if files["./LICENSE.txt"] != licenseSHA256 {
+		t.Fatalf("expected license checksum %s, files: %#v", licenseSHA256, files)
+	}
+
+	if !hasSPDXModule(doc, "github.com/hashicorp/go-version") {
+		t.Fatalf("expected go-version module in packages: %#v", doc.Packages)
+	}
+}
+
+func hasSPDXModule(doc spdxDocument, path string) bool {
+	for _, p := range doc.Packages[1:] {
+		if p.Name == path && p.PrimaryPackagePurpose == "LIBRARY" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGenerate_cyclonedx(t *testing.T) {
+	t.Parallel()
+
+	s := testSubject(t)
+	var buf bytes.Buffer
+	if err := Generate(&buf, CycloneDX, s); err != nil {
+		t.Fatal(err)
+	}
+
+	var bom cdxBOM
+	if err := json.Unmarshal(buf.Bytes(), &bom); err != nil {
+		t.Fatal(err)
+	}
+
+	if bom.BOMFormat != "CycloneDX" || bom.SpecVersion != "1.5" {
+		t.Fatalf("unexpected format: %s %s", bom.BOMFormat, bom.SpecVersion)
+	}
+	if !strings.HasPrefix(bom.SerialNumber, "urn:uuid:") {
+		t.Fatalf("unexpected serial number: %q", bom.SerialNumber)
+	}
+
+	product := bom.Metadata.Component
+	if product.Name != "tofu" || product.Version != "1.6.2" {
+		t.Fatalf("unexpected product: %s %s", product.Name, product.Version)
+	}
+	if len(product.ExternalReferences) != 1 ||
+		product.ExternalReferences[0].URL != s.Download.URL ||
+		product.ExternalReferences[0].Hashes[0].Content != s.Download.SHA256 {
+		t.Fatalf("unexpected external references: %#v", product.ExternalReferences)
+	}
+
+	var foundLicense, foundModule bool
+	for _, c := range bom.Components {
+		switch {
+		case c.Type == "file" && c.Name == "LICENSE.txt":
+			foundLicense = c.Hashes[0].Content == licenseSHA256
+		case c.Type == "library" && c.Name == "github.com/hashicorp/go-version":
+			foundModule = strings.HasPrefix(c.PURL, "pkg:golang/github.com/hashicorp/go-version@v")
+		}
+	}
+	if !foundLicense {
+		t.Fatalf("expected license file in components: %#v", bom.Components)
+	}
+	if !foundModule {
+		t.Fatalf("expected go-version module in components: %#v", bom.Components)
+	}
+	if len(bom.Dependencies) != 1 || bom.Dependencies[0].Ref != product.BOMRef {
+		t.Fatalf("unexpected dependencies: %#v", bom.Dependencies)
+	}
+}
+
+func TestGenerate_notGoBinary(t *testing.T) {
+	t.Parallel()
+
+	execPath := filepath.Join(t.TempDir(), "mytool")
+	if err := os.WriteFile(execPath, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err := Generate(&buf, SPDX, Subject{ExecPath: execPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc spdxDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Packages) != 1 || doc.Packages[0].Name != "mytool" {
+		t.Fatalf("expected only the product named after the binary, given %#v", doc.Packages)
+	}
+	if doc.Packages[0].DownloadLocation != spdxNoAssertion {
+		t.Fatalf("unexpected download location: %q", doc.Packages[0].DownloadLocation)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, f := range []Format{SPDX, CycloneDX} {
+		parsed, err := ParseFormat(f.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed != f {
+			t.Fatalf("expected %s, given %s", f, parsed)
+		}
+	}
+
+	if _, err := ParseFormat("swid"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
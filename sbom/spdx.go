@@ -0,0 +1,234 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package sbom
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	lfversion "github.com/chushi-io/lf-install/version"
+)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Files             []spdxFile         `json:"files,omitempty"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID                string            `json:"SPDXID"`
+	Name                  string            `json:"name"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	PackageFileName       string            `json:"packageFileName,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	VerificationCode      *spdxVerification `json:"packageVerificationCode,omitempty"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	LicenseConcluded      string            `json:"licenseConcluded"`
+	LicenseDeclared       string            `json:"licenseDeclared"`
+	CopyrightText         string            `json:"copyrightText"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+}
+
+type spdxFile struct {
+	SPDXID           string         `json:"SPDXID"`
+	FileName         string         `json:"fileName"`
+	FileTypes        []string       `json:"fileTypes,omitempty"`
+	Checksums        []spdxChecksum `json:"checksums"`
+	LicenseConcluded string         `json:"licenseConcluded"`
+	CopyrightText    string         `json:"copyrightText"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxVerification struct {
+	Value string `json:"packageVerificationCodeValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+const spdxNoAssertion = "NOASSERTION"
+
+// spdxInvalidIDChars matches characters not allowed in SPDX identifiers
+var spdxInvalidIDChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+func spdxID(kind, name string) string {
+	return "SPDXRef-" + kind + "-" + spdxInvalidIDChars.ReplaceAllString(name, "-")
+}
+
+// spdxFileChecksums returns checksums of the file,
+// including SHA-1 which SPDX requires for every file
+func spdxFileChecksums(f file) []spdxChecksum {
+	return []spdxChecksum{
+		{Algorithm: "SHA256", ChecksumValue: f.sha256},
+		{Algorithm: "SHA1", ChecksumValue: f.sha1},
+	}
+}
+
+// spdxVerificationCode computes the package verification code,
+// i.e. SHA-1 of sorted SHA-1 checksums of all files in the package
+func spdxVerificationCode(files []file) string {
+	sums := make([]string, 0, len(files))
+	for _, f := range files {
+		sums = append(sums, f.sha1)
+	}
+	sort.Strings(sums)
+
+	sum := sha1.Sum([]byte(strings.Join(sums, "")))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeSPDX(w io.Writer, inv *inventory) error {
+	id, err := newUUID()
+	if err != nil {
+		return err
+	}
+
+	name := inv.Product
+	if v := inv.version(); v != "" {
+		name += "-" + v
+	}
+
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              name,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/lf-install/%s-%s", name, id),
+		CreationInfo: spdxCreationInfo{
+			Created:  inv.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: lf-install-" + lfversion.Version().String()},
+		},
+	}
+
+	productID := spdxID("Package", inv.Product)
+	product := spdxPackage{
+		SPDXID:                productID,
+		Name:                  inv.Product,
+		VersionInfo:           inv.version(),
+		DownloadLocation:      spdxNoAssertion,
+		LicenseConcluded:      spdxNoAssertion,
+		LicenseDeclared:       spdxNoAssertion,
+		CopyrightText:         spdxNoAssertion,
+		PrimaryPackagePurpose: "APPLICATION",
+	}
+	if d := inv.Download; d != nil {
+		product.DownloadLocation = d.URL
+		product.PackageFileName = d.Filename
+		if d.SHA256 != "" {
+			product.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: d.SHA256}}
+		}
+	}
+	if purl := inv.purl(); purl != "" {
+		product.ExternalRefs = []spdxExternalRef{{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  purl,
+		}}
+	}
+	// the package contains the files below, which
+	// SPDX only allows for packages with files analyzed
+	product.FilesAnalyzed = true
+	product.VerificationCode = &spdxVerification{
+		Value: spdxVerificationCode(append([]file{inv.binary}, inv.licenses...)),
+	}
+	doc.Packages = append(doc.Packages, product)
+	doc.Relationships = append(doc.Relationships, spdxRelationship{
+		SPDXElementID:      doc.SPDXID,
+		RelationshipType:   "DESCRIBES",
+		RelatedSPDXElement: productID,
+	})
+
+	binaryID := spdxID("File", inv.binary.name)
+	doc.Files = append(doc.Files, spdxFile{
+		SPDXID:           binaryID,
+		FileName:         "./" + inv.binary.name,
+		FileTypes:        []string{"BINARY"},
+		Checksums:        spdxFileChecksums(inv.binary),
+		LicenseConcluded: spdxNoAssertion,
+		CopyrightText:    spdxNoAssertion,
+	})
+	doc.Relationships = append(doc.Relationships, spdxRelationship{
+		SPDXElementID:      productID,
+		RelationshipType:   "CONTAINS",
+		RelatedSPDXElement: binaryID,
+	})
+
+	for _, l := range inv.licenses {
+		fileID := spdxID("File", l.name)
+		doc.Files = append(doc.Files, spdxFile{
+			SPDXID:           fileID,
+			FileName:         "./" + l.name,
+			FileTypes:        []string{"TEXT"},
+			Checksums:        spdxFileChecksums(l),
+			LicenseConcluded: spdxNoAssertion,
+			CopyrightText:    spdxNoAssertion,
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      productID,
+			RelationshipType:   "CONTAINS",
+			RelatedSPDXElement: fileID,
+		})
+	}
+
+	for _, m := range inv.modules {
+		moduleID := spdxID("Module", m.path+"-"+m.version)
+		doc.Packages = append(doc.Packages, spdxPackage{
+			SPDXID:           moduleID,
+			Name:             m.path,
+			VersionInfo:      m.version,
+			DownloadLocation: spdxNoAssertion,
+			LicenseConcluded: spdxNoAssertion,
+			LicenseDeclared:  spdxNoAssertion,
+			CopyrightText:    spdxNoAssertion,
+			ExternalRefs: []spdxExternalRef{{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  goPURL(m.path, m.version),
+			}},
+			PrimaryPackagePurpose: "LIBRARY",
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      binaryID,
+			RelationshipType:   "STATIC_LINK",
+			RelatedSPDXElement: moduleID,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -8,6 +8,7 @@ import (
 	"log"
 
 	isrc "github.com/chushi-io/lf-install/internal/src"
+	"github.com/hashicorp/go-version"
 )
 
 // Source represents an installer, finder, or builder
@@ -40,6 +41,84 @@ type Removable interface {
 	Remove(ctx context.Context) error
 }
 
+// Committable represents a source which replaces existing files
+// on installation and can keep backups of them until the installation
+// is committed (e.g. once all hooks succeeded). Remove restores
+// any backups still kept.
+type Committable interface {
+	Source
+	SetKeepBackups(keep bool)
+	Commit()
+}
+
+// Describable represents a source which can describe
+// the result of its last Find, Install or Build call
+// (or just the product, if there was no such call)
+type Describable interface {
+	Source
+	Details() Details
+}
+
+// Details describes a binary found, installed or built by a source
+type Details struct {
+	// Product represents name of the product (empty if unknown)
+	Product string
+
+	// Version represents the resolved version of the product
+	// (nil if unknown)
+	Version *version.Version
+
+	// Files represents paths of all files and directories
+	// created by the source (none for sources which only find binaries)
+	Files []string
+
+	// Advisories represents known vulnerabilities affecting
+	// the version (only populated when advisories were checked)
+	Advisories []Advisory
+
+	// Download describes the archive the product was installed from
+	// (nil for sources which don't download archives)
+	Download *Download
+
+	// Revision represents the commit the product was built from
+	// (empty for sources which don't build from source)
+	Revision string
+}
+
+// Download describes a downloaded archive
+type Download struct {
+	URL      string
+	Filename string
+
+	// SHA256 represents hex-encoded SHA-256 checksum of the archive
+	SHA256 string
+}
+
+// Advisory describes a known vulnerability affecting a version
+type Advisory struct {
+	// ID represents the identifier of the advisory (e.g. GO-2024-0001)
+	ID string
+
+	// Aliases represents other identifiers of the same vulnerability (e.g. CVEs)
+	Aliases []string
+
+	Summary string
+
+	// FixedVersions represents versions in which the vulnerability was fixed
+	FixedVersions []string
+}
+
+// PostDownloadHookSettable represents a source which downloads archives
+// and can report them after download, prior to installation
+type PostDownloadHookSettable interface {
+	Source
+	SetPostDownloadHook(hook PostDownloadHookFunc)
+}
+
+// PostDownloadHookFunc is called with path to a downloaded (and verified)
+// archive. Returning an error aborts the installation.
+type PostDownloadHookFunc func(ctx context.Context, details Details, archivePath string) error
+
 type LoggerSettable interface {
 	SetLogger(logger *log.Logger)
 }